@@ -2,6 +2,7 @@ package assert
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"testing"
 )
@@ -24,3 +25,19 @@ func HTTPResponseBodyJSON[B any](assertions ...Assertion[B]) Assertion[*http.Res
 		}
 	}
 }
+
+func HTTPResponseHeader(key string, assertions ...Assertion[string]) Assertion[*http.Response] {
+	return func(t *testing.T, got *http.Response) {
+		Assert(t, got.Header.Get(key), assertions)
+	}
+}
+
+func HTTPResponseBody(assertions ...Assertion[[]byte]) Assertion[*http.Response] {
+	return func(t *testing.T, got *http.Response) {
+		if body, err := io.ReadAll(got.Body); err != nil {
+			t.Fatal(err)
+		} else {
+			Assert(t, body, assertions)
+		}
+	}
+}
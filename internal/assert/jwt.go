@@ -1,9 +1,11 @@
 package assert
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/lestrrat-go/jwx/v3/jws"
 	"github.com/lestrrat-go/jwx/v3/jwt"
 )
 
@@ -142,3 +144,28 @@ func JWTSubject(assertions ...Assertion[string]) Assertion[jwt.Token] {
 		}
 	}
 }
+
+func JWTClaim(name string, assertions ...Assertion[string]) Assertion[jwt.Token] {
+	return func(t *testing.T, got jwt.Token) {
+		var value any
+		if err := got.Get(name, &value); err != nil {
+			t.Errorf("JWT %s: no such claim", name)
+		} else if data, err := json.Marshal(value); err != nil {
+			t.Errorf("JWT %s: malformed claim: %v", name, err)
+		} else {
+			Assert(t, string(data), assertions)
+		}
+	}
+}
+
+func JWSAlgorithm(want string, msg ...string) Assertion[string] {
+	return func(t *testing.T, got string) {
+		if m, err := jws.ParseString(got); err != nil {
+			t.Fatalf("malformed JWS input")
+		} else if alg, ok := m.Signatures()[0].ProtectedHeaders().Algorithm(); !ok {
+			t.Error(format(msg, "JWS algorithm: no such header"))
+		} else if alg.String() != want {
+			t.Error(format(msg, "JWS algorithm: got %s, want %s", alg, want))
+		}
+	}
+}
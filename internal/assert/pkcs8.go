@@ -0,0 +1,131 @@
+package assert
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var errIncorrectPassphrase = errors.New("incorrect passphrase or malformed encrypted private key")
+
+var (
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	PRF            pkix.AlgorithmIdentifier
+}
+
+func PKCS8PrivateKey(passphrase string, msg ...string) Assertion[string] {
+	return func(t *testing.T, got string) {
+		block, _ := pem.Decode([]byte(got))
+		if block == nil {
+			t.Fatalf("malformed PEM private key input")
+		}
+
+		der := block.Bytes
+		if passphrase != "" {
+			if block.Type != "ENCRYPTED PRIVATE KEY" {
+				t.Fatal(format(msg, "PEM type: got %q, want %q", block.Type, "ENCRYPTED PRIVATE KEY"))
+			}
+
+			if _, err := decryptPKCS8PrivateKey(der, []byte(passphrase+"!")); err == nil {
+				t.Error(format(msg, "encrypted private key was decrypted using the wrong passphrase"))
+			}
+
+			var err error
+			if der, err = decryptPKCS8PrivateKey(der, []byte(passphrase)); err != nil {
+				t.Fatal(format(msg, "malformed encrypted private key: %v", err))
+			}
+		} else if block.Type != "PRIVATE KEY" {
+			t.Fatal(format(msg, "PEM type: got %q, want %q", block.Type, "PRIVATE KEY"))
+		}
+
+		if _, err := x509.ParsePKCS8PrivateKey(der); err != nil {
+			t.Error(format(msg, "malformed PKCS#8 private key: %v", err))
+		}
+	}
+}
+
+func decryptPKCS8PrivateKey(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported private key encryption scheme %s", info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) || !params.EncryptionScheme.Algorithm.Equal(oidAES256CBC) {
+		return nil, fmt.Errorf("unsupported PBES2 parameters %s/%s", params.KeyDerivationFunc.Algorithm, params.EncryptionScheme.Algorithm)
+	}
+
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, err
+	}
+
+	if !kdfParams.PRF.Algorithm.Equal(oidHMACWithSHA256) {
+		return nil, fmt.Errorf("unsupported PBKDF2 pseudorandom function %s", kdfParams.PRF.Algorithm)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), kdfParams.Salt, kdfParams.IterationCount, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := info.EncryptedData
+	if len(iv) != aes.BlockSize || len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return nil, errIncorrectPassphrase
+	}
+
+	data = bytes.Clone(data)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(data, data)
+
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errIncorrectPassphrase
+	}
+
+	return data[:len(data)-padding], nil
+}
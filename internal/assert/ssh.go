@@ -0,0 +1,72 @@
+package assert
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func SSHCertificate(assertions ...Assertion[*ssh.Certificate]) Assertion[string] {
+	return func(t *testing.T, got string) {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(got))
+		if err != nil {
+			t.Fatalf("malformed SSH public key input")
+		}
+
+		if cert, ok := key.(*ssh.Certificate); !ok {
+			t.Fatalf("SSH public key is not a certificate but a %s", key.Type())
+		} else {
+			Assert(t, cert, assertions)
+		}
+	}
+}
+
+func SSHCertType(want uint32, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if got.CertType != want {
+			t.Error(format(msg, "SSH certificate type: got %d, want %d", got.CertType, want))
+		}
+	}
+}
+
+func SSHPrincipal(want string, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if !slices.Contains(got.ValidPrincipals, want) {
+			t.Error(format(msg, "SSH principals: got %q, does not include %q", got.ValidPrincipals, want))
+		}
+	}
+}
+
+func SSHValidity(after, before uint64, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if got.ValidAfter != after || got.ValidBefore != before {
+			t.Error(format(msg, "SSH validity: got %d-%d, want %d-%d", got.ValidAfter, got.ValidBefore, after, before))
+		}
+	}
+}
+
+func SSHCriticalOption(name, want string, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if value, ok := got.CriticalOptions[name]; !ok || value != want {
+			t.Error(format(msg, "SSH critical options: got %q, does not include %s=%q", got.CriticalOptions, name, want))
+		}
+	}
+}
+
+func SSHExtension(name string, want bool, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if _, ok := got.Extensions[name]; ok != want {
+			t.Error(format(msg, "SSH extension %q: got %t, want %t", name, ok, want))
+		}
+	}
+}
+
+func SSHSignatureKey(want ssh.PublicKey, msg ...string) Assertion[*ssh.Certificate] {
+	return func(t *testing.T, got *ssh.Certificate) {
+		if !bytes.Equal(got.SignatureKey.Marshal(), want.Marshal()) {
+			t.Error(format(msg, "SSH signature key: got %s, want %s", ssh.FingerprintSHA256(got.SignatureKey), ssh.FingerprintSHA256(want)))
+		}
+	}
+}
@@ -35,6 +35,16 @@ func StringContains(want string, msg ...string) Assertion[string] {
 	}
 }
 
+func StringPrefix(want string, msg ...string) Assertion[string] {
+	return func(t *testing.T, got string) {
+		if strings.HasPrefix(got, want) {
+			return
+		}
+
+		t.Error(format(msg, "got %q, does not start with %q", got, want))
+	}
+}
+
 func StringLongerThan(want int, msg ...string) Assertion[string] {
 	return func(t *testing.T, got string) {
 		if len(got) > want {
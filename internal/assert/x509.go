@@ -0,0 +1,121 @@
+package assert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"slices"
+	"testing"
+)
+
+func X509Certificate(assertions ...Assertion[*x509.Certificate]) Assertion[string] {
+	return func(t *testing.T, got string) {
+		block, _ := pem.Decode([]byte(got))
+		if block == nil || block.Type != "CERTIFICATE" {
+			t.Fatalf("malformed PEM certificate input")
+		}
+
+		if cert, err := x509.ParseCertificate(block.Bytes); err != nil {
+			t.Fatalf("malformed X.509 certificate input")
+		} else {
+			Assert(t, cert, assertions)
+		}
+	}
+}
+
+func X509IsCA(want bool, msg ...string) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		if got.IsCA != want {
+			t.Error(format(msg, "X.509 CA: got %t, want %t", got.IsCA, want))
+		}
+	}
+}
+
+func X509SubjectCommonName(assertions ...Assertion[string]) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		Assert(t, got.Subject.CommonName, assertions)
+	}
+}
+
+func X509IssuerCommonName(assertions ...Assertion[string]) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		Assert(t, got.Issuer.CommonName, assertions)
+	}
+}
+
+func X509DNSName(want string, msg ...string) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		if !slices.Contains(got.DNSNames, want) {
+			t.Error(format(msg, "X.509 DNS names: got %q, does not include %q", got.DNSNames, want))
+		}
+	}
+}
+
+func X509ExtKeyUsage(want x509.ExtKeyUsage, msg ...string) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		if !slices.Contains(got.ExtKeyUsage, want) {
+			t.Error(format(msg, "X.509 extended key usage: got %v, does not include %v", got.ExtKeyUsage, want))
+		}
+	}
+}
+
+func X509EmailAddress(want string, msg ...string) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		if !slices.Contains(got.EmailAddresses, want) {
+			t.Error(format(msg, "X.509 email addresses: got %q, does not include %q", got.EmailAddresses, want))
+		}
+	}
+}
+
+func X509URI(want string, msg ...string) Assertion[*x509.Certificate] {
+	return func(t *testing.T, got *x509.Certificate) {
+		for _, u := range got.URIs {
+			if u.String() == want {
+				return
+			}
+		}
+
+		t.Error(format(msg, "X.509 URIs: got %v, does not include %q", got.URIs, want))
+	}
+}
+
+func X509CertificateCount(want int, msg ...string) Assertion[string] {
+	return func(t *testing.T, got string) {
+		count := 0
+		rest := []byte(got)
+
+		for {
+			var block *pem.Block
+
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+
+			if block.Type == "CERTIFICATE" {
+				count++
+			}
+		}
+
+		if count != want {
+			t.Error(format(msg, "X.509 certificate count: got %d, want %d", count, want))
+		}
+	}
+}
+
+func X509RevocationList(assertions ...Assertion[*x509.RevocationList]) Assertion[[]byte] {
+	return func(t *testing.T, got []byte) {
+		if crl, err := x509.ParseRevocationList(got); err != nil {
+			t.Fatalf("malformed X.509 revocation list input")
+		} else {
+			Assert(t, crl, assertions)
+		}
+	}
+}
+
+func X509RevokedCount(want int, msg ...string) Assertion[*x509.RevocationList] {
+	return func(t *testing.T, got *x509.RevocationList) {
+		if count := len(got.RevokedCertificateEntries); count != want {
+			t.Error(format(msg, "X.509 revoked certificates: got %d, want %d", count, want))
+		}
+	}
+}
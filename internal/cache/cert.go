@@ -1,10 +1,7 @@
 package cache
 
 import (
-	"crypto/ecdsa"
-	"crypto/ed25519"
 	"crypto/rand"
-	"crypto/rsa"
 	"crypto/x509"
 	"fmt"
 	"hash/maphash"
@@ -18,6 +15,7 @@ type CertLoader struct {
 	Parent   *x509.Certificate
 	Template *x509.Certificate
 	Key      any
+	Signer   any
 	Random   io.Reader
 }
 
@@ -28,9 +26,11 @@ func (l *CertLoader) Hash(seed maphash.Seed) uint64 {
 
 	if l.Parent != nil {
 		hashCertificate(&h, l.Parent)
+		_, _ = h.Write(l.Parent.RawSubjectPublicKeyInfo)
 	}
 
 	hashCertificate(&h, l.Template)
+	hashPublicKey(&h, crypto.PublicKey(l.Key))
 
 	return h.Sum64()
 }
@@ -60,6 +60,20 @@ func hashCertificate(h *maphash.Hash, cert *x509.Certificate) {
 	for _, u := range cert.URIs {
 		_, _ = h.WriteString(u.String())
 	}
+
+	for _, u := range cert.CRLDistributionPoints {
+		_, _ = h.WriteString(u)
+	}
+
+	for _, u := range cert.OCSPServer {
+		_, _ = h.WriteString(u)
+	}
+}
+
+func hashPublicKey(h *maphash.Hash, key any) {
+	if der, err := x509.MarshalPKIXPublicKey(key); err == nil {
+		_, _ = h.Write(der)
+	}
 }
 
 func (l *CertLoader) Load() (cert crypto.Certificate, err error) {
@@ -81,17 +95,17 @@ func (l *CertLoader) Load() (cert crypto.Certificate, err error) {
 		parent = l.Template
 	}
 
-	switch k := l.Key.(type) {
-	case *rsa.PrivateKey:
-		cert, err = x509.CreateCertificate(r, l.Template, parent, &k.PublicKey, l.Key)
-	case *ecdsa.PrivateKey:
-		cert, err = x509.CreateCertificate(r, l.Template, parent, &k.PublicKey, l.Key)
-	case ed25519.PrivateKey:
-		cert, err = x509.CreateCertificate(r, l.Template, parent, k.Public().(ed25519.PublicKey), l.Key)
-	default:
+	signer := l.Signer
+	if signer == nil {
+		signer = l.Key
+	}
+
+	pub := crypto.PublicKey(l.Key)
+	if pub == nil {
 		return nil, fmt.Errorf("unable to generate certificate for key type %T", l.Key)
 	}
 
+	cert, err = x509.CreateCertificate(r, l.Template, parent, pub, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/hotp"
 
+	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 	"github.com/UiP9AV6Y/fake-secrets/internal/hash"
 )
 
@@ -16,6 +17,7 @@ type HOTPLoader struct {
 	AccountName string
 	SecretSize  uint
 	Algorithm   hash.Algorithm
+	Digits      crypto.OTPDigits
 	Random      io.Reader
 }
 
@@ -26,6 +28,7 @@ func (l *HOTPLoader) Hash(seed maphash.Seed) uint64 {
 	_, _ = h.WriteString(l.Issuer)
 	_, _ = h.WriteString(l.AccountName)
 	_, _ = h.WriteString(l.Algorithm.String())
+	_, _ = h.WriteString(l.Digits.String())
 	_, _ = h.Write(Uint64Bytes(uint64(l.SecretSize)))
 
 	return h.Sum64()
@@ -37,6 +40,7 @@ func (l *HOTPLoader) Load() (key *otp.Key, err error) {
 		AccountName: l.AccountName,
 		SecretSize:  l.SecretSize,
 		Algorithm:   l.Algorithm.OTPAlgorithm(),
+		Digits:      l.Digits.Digits(),
 	}
 
 	if l.Random == nil {
@@ -50,5 +54,5 @@ func (l *HOTPLoader) Load() (key *otp.Key, err error) {
 		return nil, err
 	}
 
-	return
+	return withOTPEncoder(key, l.Digits.Encoder())
 }
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"net/url"
+
+	"github.com/pquerna/otp"
+)
+
+func withOTPEncoder(key *otp.Key, encoder otp.Encoder) (*otp.Key, error) {
+	if encoder == otp.EncoderDefault {
+		return key, nil
+	}
+
+	u, err := url.Parse(key.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	q.Set("encoder", string(encoder))
+	u.RawQuery = q.Encode()
+
+	return otp.NewKeyFromURL(u.String())
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"crypto/rand"
+	"hash/maphash"
+	"io"
+)
+
+type SecretLoader struct {
+	Hostname string
+	Length   int
+	Random   io.Reader
+}
+
+func (l *SecretLoader) Hash(seed maphash.Seed) uint64 {
+	var h maphash.Hash
+
+	h.SetSeed(seed)
+	_, _ = h.WriteString(l.Hostname)
+	_, _ = h.Write(Uint32Bytes(uint32(l.Length)))
+
+	return h.Sum64()
+}
+
+func (l *SecretLoader) Load() ([]byte, error) {
+	secret := make([]byte, l.Length)
+	rnd := l.Random
+	if rnd == nil {
+		rnd = rand.Reader
+	}
+
+	if _, err := io.ReadFull(rnd, secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
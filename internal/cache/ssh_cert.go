@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"hash/maphash"
+	"io"
+	"maps"
+	"slices"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
+)
+
+type SSHCertLoader struct {
+	Template *ssh.Certificate
+	Key      any
+	Signer   any
+	Random   io.Reader
+}
+
+func (l *SSHCertLoader) Hash(seed maphash.Seed) uint64 {
+	var h maphash.Hash
+
+	h.SetSeed(seed)
+
+	_ = binary.Write(&h, binary.BigEndian, l.Template.CertType)
+	_ = binary.Write(&h, binary.BigEndian, l.Template.ValidAfter)
+	_ = binary.Write(&h, binary.BigEndian, l.Template.ValidBefore)
+	_, _ = h.WriteString(l.Template.KeyId)
+
+	for _, p := range l.Template.ValidPrincipals {
+		_, _ = h.WriteString(p)
+	}
+
+	hashOptions(&h, l.Template.CriticalOptions)
+	hashOptions(&h, l.Template.Extensions)
+	hashSSHPublicKey(&h, l.Key)
+	hashSSHPublicKey(&h, l.Signer)
+
+	return h.Sum64()
+}
+
+func hashOptions(h *maphash.Hash, options map[string]string) {
+	for _, k := range slices.Sorted(maps.Keys(options)) {
+		_, _ = h.WriteString(k)
+		_, _ = h.WriteString(options[k])
+	}
+}
+
+func hashSSHPublicKey(h *maphash.Hash, key any) {
+	if pub, err := ssh.NewPublicKey(crypto.PublicKey(key)); err == nil {
+		_, _ = h.Write(pub.Marshal())
+	}
+}
+
+func (l *SSHCertLoader) Load() (cert *ssh.Certificate, err error) {
+	var r io.Reader
+	if l.Random == nil {
+		r = rand.Reader
+	} else {
+		r = l.Random
+	}
+
+	key, err := ssh.NewPublicKey(crypto.PublicKey(l.Key))
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.NewSignerFromKey(l.Signer)
+	if err != nil {
+		return nil, err
+	}
+
+	cert = &ssh.Certificate{
+		Key:             key,
+		CertType:        l.Template.CertType,
+		KeyId:           l.Template.KeyId,
+		ValidPrincipals: l.Template.ValidPrincipals,
+		ValidAfter:      l.Template.ValidAfter,
+		ValidBefore:     l.Template.ValidBefore,
+		Permissions:     l.Template.Permissions,
+	}
+
+	if err := binary.Read(r, binary.BigEndian, &cert.Serial); err != nil {
+		return nil, err
+	}
+
+	if err := cert.SignCert(r, signer); err != nil {
+		return nil, err
+	}
+
+	return cert, nil
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"slices"
+	"sync"
+)
+
+type Store[K comparable, V any] interface {
+	Get(K) (V, bool)
+	Put(K, V)
+	Delete(K) (V, bool)
+	DeleteFunc(func(K, V) bool)
+	Update(K, func(V, bool) (V, error)) (V, error)
+	Values() []V
+}
+
+type mapStore[K comparable, V any] struct {
+	store map[K]V
+	keys  []K
+	lock  sync.RWMutex
+}
+
+func NewStore[K comparable, V any]() Store[K, V] {
+	result := &mapStore[K, V]{
+		store: map[K]V{},
+	}
+
+	return result
+}
+
+func (s *mapStore[K, V]) Get(k K) (value V, ok bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	value, ok = s.store[k]
+
+	return
+}
+
+func (s *mapStore[K, V]) Put(k K, v V) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.put(k, v)
+}
+
+func (s *mapStore[K, V]) put(k K, v V) {
+	if _, ok := s.store[k]; !ok {
+		s.keys = append(s.keys, k)
+	}
+
+	s.store[k] = v
+}
+
+func (s *mapStore[K, V]) Delete(k K) (value V, ok bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, ok = s.store[k]
+	if !ok {
+		return
+	}
+
+	delete(s.store, k)
+	s.keys = slices.DeleteFunc(s.keys, func(e K) bool {
+		return e == k
+	})
+
+	return
+}
+
+func (s *mapStore[K, V]) DeleteFunc(f func(K, V) bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.keys = slices.DeleteFunc(s.keys, func(k K) bool {
+		if !f(k, s.store[k]) {
+			return false
+		}
+
+		delete(s.store, k)
+
+		return true
+	})
+}
+
+func (s *mapStore[K, V]) Update(k K, f func(V, bool) (V, error)) (value V, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, ok := s.store[k]
+	value, err = f(current, ok)
+	if err != nil {
+		return
+	}
+
+	s.put(k, value)
+
+	return
+}
+
+func (s *mapStore[K, V]) Values() []V {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	result := make([]V, 0, len(s.keys))
+	for _, k := range s.keys {
+		result = append(result, s.store[k])
+	}
+
+	return result
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
 
+	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 	"github.com/UiP9AV6Y/fake-secrets/internal/hash"
 )
 
@@ -17,6 +18,7 @@ type TOTPLoader struct {
 	Period      uint
 	SecretSize  uint
 	Algorithm   hash.Algorithm
+	Digits      crypto.OTPDigits
 	Random      io.Reader
 }
 
@@ -27,6 +29,7 @@ func (l *TOTPLoader) Hash(seed maphash.Seed) uint64 {
 	_, _ = h.WriteString(l.Issuer)
 	_, _ = h.WriteString(l.AccountName)
 	_, _ = h.WriteString(l.Algorithm.String())
+	_, _ = h.WriteString(l.Digits.String())
 	_, _ = h.Write(Uint64Bytes(uint64(l.SecretSize)))
 	_, _ = h.Write(Uint64Bytes(uint64(l.Period)))
 
@@ -39,6 +42,7 @@ func (l *TOTPLoader) Load() (key *otp.Key, err error) {
 		AccountName: l.AccountName,
 		SecretSize:  l.SecretSize,
 		Algorithm:   l.Algorithm.OTPAlgorithm(),
+		Digits:      l.Digits.Digits(),
 		Period:      l.Period,
 	}
 
@@ -53,5 +57,5 @@ func (l *TOTPLoader) Load() (key *otp.Key, err error) {
 		return nil, err
 	}
 
-	return
+	return withOTPEncoder(key, l.Digits.Encoder())
 }
@@ -83,6 +83,8 @@ func PublicKey(key any) any {
 		return &k.PublicKey
 	case ed25519.PrivateKey:
 		return k.Public().(ed25519.PublicKey)
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return k
 	default:
 		return nil
 	}
@@ -0,0 +1,69 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Authority int
+
+const (
+	AuthorityNone Authority = iota
+	AuthorityRoot
+	AuthorityIntermediate
+)
+
+var authorityImpl = map[string]Authority{
+	"NONE":         AuthorityNone,
+	"SELF":         AuthorityNone,
+	"ROOT":         AuthorityRoot,
+	"INTERMEDIATE": AuthorityIntermediate,
+}
+
+func ParseAuthority(a string) (authority Authority, err error) {
+	if a == "" {
+		authority = AuthorityNone
+		return
+	}
+
+	err = (&authority).UnmarshalText([]byte(a))
+
+	return
+}
+
+func (a *Authority) UnmarshalText(text []byte) error {
+	authority, ok := authorityImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid certificate authority %q", text)
+	}
+
+	*a = authority
+
+	return nil
+}
+
+func (a Authority) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a Authority) String() string {
+	switch a {
+	case AuthorityNone:
+		return "NONE"
+	case AuthorityRoot:
+		return "ROOT"
+	case AuthorityIntermediate:
+		return "INTERMEDIATE"
+	default:
+		return "unknown certificate authority " + strconv.Itoa(int(a))
+	}
+}
+
+func (a Authority) Parent() Authority {
+	if a == AuthorityIntermediate {
+		return AuthorityRoot
+	}
+
+	return AuthorityNone
+}
@@ -1,11 +1,13 @@
 package crypto
 
 import (
+	"crypto/ecdh"
 	"crypto/elliptic"
 	"fmt"
 	"strconv"
 	"strings"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/lestrrat-go/jwx/v3/jwa"
 )
 
@@ -16,6 +18,7 @@ const (
 	ECDSACurveP256
 	ECDSACurveP384
 	ECDSACurveP521
+	ECDSACurveSecp256k1
 )
 
 var ecdsaCurves = map[ECDSACurve]elliptic.Curve{
@@ -23,13 +26,23 @@ var ecdsaCurves = map[ECDSACurve]elliptic.Curve{
 	ECDSACurveP256: elliptic.P256(),
 	ECDSACurveP384: elliptic.P384(),
 	ECDSACurveP521: elliptic.P521(),
+	// neither x509 nor ssh are able to encode keys on this curve,
+	// so only the JWT routes accept it
+	ECDSACurveSecp256k1: secp256k1.S256(),
 }
 
 var ecdsaSignatures = map[ECDSACurve]jwa.SignatureAlgorithm{
 	// jwx does not provide any SHA224-based signers
-	ECDSACurveP256: jwa.ES256(),
-	ECDSACurveP384: jwa.ES384(),
-	ECDSACurveP521: jwa.ES512(),
+	ECDSACurveP256:      jwa.ES256(),
+	ECDSACurveP384:      jwa.ES384(),
+	ECDSACurveP521:      jwa.ES512(),
+	ECDSACurveSecp256k1: jwa.ES256K(),
+}
+
+var ecdhCurves = map[ECDSACurve]ecdh.Curve{
+	ECDSACurveP256: ecdh.P256(),
+	ECDSACurveP384: ecdh.P384(),
+	ECDSACurveP521: ecdh.P521(),
 }
 
 var ecdsaImpl = map[string]ECDSACurve{
@@ -45,6 +58,7 @@ var ecdsaImpl = map[string]ECDSACurve{
 	"P521":      ECDSACurveP521,
 	"P-521":     ECDSACurveP521,
 	"SECP521R1": ECDSACurveP521,
+	"SECP256K1": ECDSACurveSecp256k1,
 }
 
 func ParseECDSACurve(c string) (curve ECDSACurve, err error) {
@@ -85,6 +99,10 @@ func (c ECDSACurve) Curve() elliptic.Curve {
 	return ecdsaCurves[c]
 }
 
+func (c ECDSACurve) ECDHCurve() ecdh.Curve {
+	return ecdhCurves[c]
+}
+
 func (c ECDSACurve) SignatureAlgorithm() jwa.SignatureAlgorithm {
 	return ecdsaSignatures[c]
 }
@@ -0,0 +1,155 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+)
+
+type JWEAlgorithm int
+
+const (
+	JWEAlgorithmNone JWEAlgorithm = iota
+	JWEAlgorithmRSAOAEP
+	JWEAlgorithmRSAOAEP256
+	JWEAlgorithmECDHES
+	JWEAlgorithmECDHESA256KW
+	JWEAlgorithmA256KW
+)
+
+var jweAlgorithmKeys = map[JWEAlgorithm]jwa.KeyEncryptionAlgorithm{
+	JWEAlgorithmRSAOAEP:      jwa.RSA_OAEP(),
+	JWEAlgorithmRSAOAEP256:   jwa.RSA_OAEP_256(),
+	JWEAlgorithmECDHES:       jwa.ECDH_ES(),
+	JWEAlgorithmECDHESA256KW: jwa.ECDH_ES_A256KW(),
+	JWEAlgorithmA256KW:       jwa.A256KW(),
+}
+
+var jweAlgorithmSecrets = map[JWEAlgorithm]int{
+	JWEAlgorithmA256KW: 32,
+}
+
+func ParseJWEAlgorithm(a string) (algo JWEAlgorithm, err error) {
+	if a == "" {
+		algo = JWEAlgorithmNone
+		return
+	}
+
+	err = (&algo).UnmarshalText([]byte(a))
+
+	return
+}
+
+func (a *JWEAlgorithm) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	for algo, key := range jweAlgorithmKeys {
+		if key.String() == name {
+			*a = algo
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid JWE key management algorithm %q", text)
+}
+
+func (a JWEAlgorithm) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a JWEAlgorithm) String() string {
+	if a == JWEAlgorithmNone {
+		return "NONE"
+	}
+
+	if key, ok := jweAlgorithmKeys[a]; ok {
+		return key.String()
+	}
+
+	return "unknown JWE key management algorithm " + strconv.Itoa(int(a))
+}
+
+func (a JWEAlgorithm) KeyEncryptionAlgorithm() jwa.KeyEncryptionAlgorithm {
+	return jweAlgorithmKeys[a]
+}
+
+func (a JWEAlgorithm) KeyAlgorithm() Algorithm {
+	switch a {
+	case JWEAlgorithmRSAOAEP, JWEAlgorithmRSAOAEP256:
+		return AlgorithmRSA
+	case JWEAlgorithmECDHES, JWEAlgorithmECDHESA256KW:
+		return AlgorithmECDSA
+	default:
+		return 0
+	}
+}
+
+func (a JWEAlgorithm) Symmetric() bool {
+	_, ok := jweAlgorithmSecrets[a]
+
+	return ok
+}
+
+func (a JWEAlgorithm) SecretLength() int {
+	return jweAlgorithmSecrets[a]
+}
+
+type JWEEncryption int
+
+const (
+	JWEEncryptionA128GCM JWEEncryption = 1 + iota
+	JWEEncryptionA192GCM
+	JWEEncryptionA256GCM
+	JWEEncryptionA128CBCHS256
+	JWEEncryptionA256CBCHS512
+)
+
+var jweEncryptionContents = map[JWEEncryption]jwa.ContentEncryptionAlgorithm{
+	JWEEncryptionA128GCM:      jwa.A128GCM(),
+	JWEEncryptionA192GCM:      jwa.A192GCM(),
+	JWEEncryptionA256GCM:      jwa.A256GCM(),
+	JWEEncryptionA128CBCHS256: jwa.A128CBC_HS256(),
+	JWEEncryptionA256CBCHS512: jwa.A256CBC_HS512(),
+}
+
+func ParseJWEEncryption(e string) (enc JWEEncryption, err error) {
+	if e == "" {
+		enc = JWEEncryptionA256GCM
+		return
+	}
+
+	err = (&enc).UnmarshalText([]byte(e))
+
+	return
+}
+
+func (e *JWEEncryption) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	for enc, content := range jweEncryptionContents {
+		if content.String() == name {
+			*e = enc
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid JWE content encryption %q", text)
+}
+
+func (e JWEEncryption) MarshalText() ([]byte, error) {
+	return []byte(e.String()), nil
+}
+
+func (e JWEEncryption) String() string {
+	if content, ok := jweEncryptionContents[e]; ok {
+		return content.String()
+	}
+
+	return "unknown JWE content encryption " + strconv.Itoa(int(e))
+}
+
+func (e JWEEncryption) ContentEncryptionAlgorithm() jwa.ContentEncryptionAlgorithm {
+	return jweEncryptionContents[e]
+}
@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lestrrat-go/jwx/v3/jwa"
+)
+
+type JWSAlgorithm int
+
+const (
+	JWSAlgorithmNone JWSAlgorithm = iota
+	JWSAlgorithmRS256
+	JWSAlgorithmRS384
+	JWSAlgorithmRS512
+	JWSAlgorithmPS256
+	JWSAlgorithmPS384
+	JWSAlgorithmPS512
+	JWSAlgorithmES256
+	JWSAlgorithmES384
+	JWSAlgorithmES512
+	JWSAlgorithmES256K
+	JWSAlgorithmEdDSA
+	JWSAlgorithmHS256
+	JWSAlgorithmHS384
+	JWSAlgorithmHS512
+)
+
+var jwsAlgorithmSignatures = map[JWSAlgorithm]jwa.SignatureAlgorithm{
+	JWSAlgorithmRS256:  jwa.RS256(),
+	JWSAlgorithmRS384:  jwa.RS384(),
+	JWSAlgorithmRS512:  jwa.RS512(),
+	JWSAlgorithmPS256:  jwa.PS256(),
+	JWSAlgorithmPS384:  jwa.PS384(),
+	JWSAlgorithmPS512:  jwa.PS512(),
+	JWSAlgorithmES256:  jwa.ES256(),
+	JWSAlgorithmES384:  jwa.ES384(),
+	JWSAlgorithmES512:  jwa.ES512(),
+	JWSAlgorithmES256K: jwa.ES256K(),
+	JWSAlgorithmEdDSA:  jwa.EdDSA(),
+	JWSAlgorithmHS256:  jwa.HS256(),
+	JWSAlgorithmHS384:  jwa.HS384(),
+	JWSAlgorithmHS512:  jwa.HS512(),
+}
+
+var jwsAlgorithmCurves = map[JWSAlgorithm]ECDSACurve{
+	JWSAlgorithmES256:  ECDSACurveP256,
+	JWSAlgorithmES384:  ECDSACurveP384,
+	JWSAlgorithmES512:  ECDSACurveP521,
+	JWSAlgorithmES256K: ECDSACurveSecp256k1,
+}
+
+var jwsAlgorithmSecrets = map[JWSAlgorithm]int{
+	JWSAlgorithmHS256: 32,
+	JWSAlgorithmHS384: 48,
+	JWSAlgorithmHS512: 64,
+}
+
+func ParseJWSAlgorithm(a string) (algo JWSAlgorithm, err error) {
+	if a == "" {
+		algo = JWSAlgorithmNone
+		return
+	}
+
+	err = (&algo).UnmarshalText([]byte(a))
+
+	return
+}
+
+func (a *JWSAlgorithm) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	for algo, sig := range jwsAlgorithmSignatures {
+		if strings.ToUpper(sig.String()) == name {
+			*a = algo
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid JWS algorithm %q", text)
+}
+
+func (a JWSAlgorithm) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a JWSAlgorithm) String() string {
+	if a == JWSAlgorithmNone {
+		return "NONE"
+	}
+
+	if sig, ok := jwsAlgorithmSignatures[a]; ok {
+		return sig.String()
+	}
+
+	return "unknown JWS algorithm " + strconv.Itoa(int(a))
+}
+
+func (a JWSAlgorithm) SignatureAlgorithm() jwa.SignatureAlgorithm {
+	return jwsAlgorithmSignatures[a]
+}
+
+func (a JWSAlgorithm) KeyAlgorithm() Algorithm {
+	switch a {
+	case JWSAlgorithmRS256, JWSAlgorithmRS384, JWSAlgorithmRS512,
+		JWSAlgorithmPS256, JWSAlgorithmPS384, JWSAlgorithmPS512:
+		return AlgorithmRSA
+	case JWSAlgorithmES256, JWSAlgorithmES384, JWSAlgorithmES512, JWSAlgorithmES256K:
+		return AlgorithmECDSA
+	case JWSAlgorithmEdDSA:
+		return AlgorithmED25519
+	default:
+		return 0
+	}
+}
+
+func (a JWSAlgorithm) ECDSACurve() ECDSACurve {
+	return jwsAlgorithmCurves[a]
+}
+
+func (a JWSAlgorithm) Symmetric() bool {
+	_, ok := jwsAlgorithmSecrets[a]
+
+	return ok
+}
+
+func (a JWSAlgorithm) SecretLength() int {
+	return jwsAlgorithmSecrets[a]
+}
@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type KeyFormat int
+
+const (
+	KeyFormatPKCS8 KeyFormat = 1 + iota
+	KeyFormatPKCS1
+	KeyFormatSEC1
+	KeyFormatDER
+	KeyFormatOpenSSH
+	KeyFormatPuTTY
+)
+
+var keyFormatImpl = map[string]KeyFormat{
+	"PKCS8":   KeyFormatPKCS8,
+	"PKCS1":   KeyFormatPKCS1,
+	"SEC1":    KeyFormatSEC1,
+	"DER":     KeyFormatDER,
+	"OPENSSH": KeyFormatOpenSSH,
+	"PUTTY":   KeyFormatPuTTY,
+	"PPK":     KeyFormatPuTTY,
+}
+
+var keyFormatAlgorithms = map[KeyFormat]Algorithm{
+	KeyFormatPKCS1: AlgorithmRSA,
+	KeyFormatSEC1:  AlgorithmECDSA,
+}
+
+func ParseKeyFormat(f string) (format KeyFormat, err error) {
+	if f == "" {
+		format = KeyFormatPKCS8
+		return
+	}
+
+	err = (&format).UnmarshalText([]byte(f))
+
+	return
+}
+
+func (f *KeyFormat) UnmarshalText(text []byte) error {
+	format, ok := keyFormatImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid private key format %q", text)
+	}
+
+	*f = format
+
+	return nil
+}
+
+func (f KeyFormat) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+func (f KeyFormat) String() string {
+	switch f {
+	case KeyFormatPKCS8:
+		return "PKCS8"
+	case KeyFormatPKCS1:
+		return "PKCS1"
+	case KeyFormatSEC1:
+		return "SEC1"
+	case KeyFormatDER:
+		return "DER"
+	case KeyFormatOpenSSH:
+		return "OPENSSH"
+	case KeyFormatPuTTY:
+		return "PUTTY"
+	default:
+		return "unknown private key format " + strconv.Itoa(int(f))
+	}
+}
+
+func (f KeyFormat) Supports(a Algorithm) bool {
+	algo, ok := keyFormatAlgorithms[f]
+
+	return !ok || algo == a
+}
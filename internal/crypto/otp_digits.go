@@ -0,0 +1,80 @@
+package crypto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pquerna/otp"
+)
+
+type OTPDigits int
+
+const (
+	OTPDigitsSix OTPDigits = 1 + iota
+	OTPDigitsEight
+	OTPDigitsSteam
+)
+
+var otpDigitsImpl = map[string]OTPDigits{
+	"6":     OTPDigitsSix,
+	"8":     OTPDigitsEight,
+	"STEAM": OTPDigitsSteam,
+}
+
+var otpDigitsLength = map[OTPDigits]otp.Digits{
+	OTPDigitsSix:   otp.DigitsSix,
+	OTPDigitsEight: otp.DigitsEight,
+	OTPDigitsSteam: otp.Digits(5),
+}
+
+func ParseOTPDigits(d string) (digits OTPDigits, err error) {
+	if d == "" {
+		digits = OTPDigitsSix
+		return
+	}
+
+	err = (&digits).UnmarshalText([]byte(d))
+
+	return
+}
+
+func (d *OTPDigits) UnmarshalText(text []byte) error {
+	digits, ok := otpDigitsImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid OTP digits %q", text)
+	}
+
+	*d = digits
+
+	return nil
+}
+
+func (d OTPDigits) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d OTPDigits) String() string {
+	switch d {
+	case OTPDigitsSix:
+		return "6"
+	case OTPDigitsEight:
+		return "8"
+	case OTPDigitsSteam:
+		return "steam"
+	default:
+		return "unknown OTP digits " + strconv.Itoa(int(d))
+	}
+}
+
+func (d OTPDigits) Digits() otp.Digits {
+	return otpDigitsLength[d]
+}
+
+func (d OTPDigits) Encoder() otp.Encoder {
+	if d == OTPDigitsSteam {
+		return otp.EncoderSteam
+	}
+
+	return otp.EncoderDefault
+}
@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	Argon2Memory      uint32 = 19456
+	Argon2Passes      uint32 = 2
+	Argon2Parallelism uint8  = 1
+	Argon2SaltSize           = 16
+	Argon2KeySize     uint32 = 32
+)
+
+type PasswordHash int
+
+const (
+	PasswordHashBcrypt PasswordHash = 1 + iota
+	PasswordHashArgon2
+)
+
+var passwordHashImpl = map[string]PasswordHash{
+	"BCRYPT":   PasswordHashBcrypt,
+	"ARGON2":   PasswordHashArgon2,
+	"ARGON2ID": PasswordHashArgon2,
+}
+
+func ParsePasswordHash(h string) (hash PasswordHash, err error) {
+	if h == "" {
+		hash = PasswordHashBcrypt
+		return
+	}
+
+	err = (&hash).UnmarshalText([]byte(h))
+
+	return
+}
+
+func (h *PasswordHash) UnmarshalText(text []byte) error {
+	hash, ok := passwordHashImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid password hash %q", text)
+	}
+
+	*h = hash
+
+	return nil
+}
+
+func (h PasswordHash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (h PasswordHash) String() string {
+	switch h {
+	case PasswordHashBcrypt:
+		return "bcrypt"
+	case PasswordHashArgon2:
+		return "argon2id"
+	default:
+		return "unknown password hash " + strconv.Itoa(int(h))
+	}
+}
+
+func (h PasswordHash) Sum(rand io.Reader, password []byte) ([]byte, error) {
+	switch h {
+	case PasswordHashBcrypt:
+		// bcrypt draws its salt from crypto/rand on its own
+		return bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+	case PasswordHashArgon2:
+		salt := make([]byte, Argon2SaltSize)
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return nil, err
+		}
+
+		key := argon2.IDKey(password, salt, Argon2Passes, Argon2Memory, Argon2Parallelism, Argon2KeySize)
+		result := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, Argon2Memory, Argon2Passes, Argon2Parallelism,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key))
+
+		return []byte(result), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash %s", h)
+	}
+}
@@ -0,0 +1,154 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	PuTTYArgon2Memory      uint32 = 8192
+	PuTTYArgon2Passes      uint32 = 13
+	PuTTYArgon2Parallelism uint8  = 1
+)
+
+type puttyMACInput struct {
+	Algorithm  string
+	Encryption string
+	Comment    string
+	Public     []byte
+	Private    []byte
+}
+
+func MarshalPuTTYPrivateKey(rand io.Reader, key any, comment string, passphrase []byte) ([]byte, error) {
+	pub, err := ssh.NewPublicKey(PublicKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := marshalPuTTYPrivateBlob(key)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption := "none"
+	salt := make([]byte, 16)
+	var derived []byte
+	var macKey []byte
+
+	if len(passphrase) > 0 {
+		encryption = "aes256-cbc"
+		if _, err := io.ReadFull(rand, salt); err != nil {
+			return nil, err
+		}
+
+		// same as puttygen, pad using the hash of the unpadded blob
+		padding := sha1.Sum(priv)
+		priv = append(priv, padding[:(aes.BlockSize-len(priv)%aes.BlockSize)%aes.BlockSize]...)
+		derived = argon2.IDKey(passphrase, salt, PuTTYArgon2Passes, PuTTYArgon2Memory, PuTTYArgon2Parallelism, 32+aes.BlockSize+32)
+		macKey = derived[32+aes.BlockSize:]
+	}
+
+	mac := hmac.New(sha256.New, macKey)
+	_, _ = mac.Write(ssh.Marshal(puttyMACInput{
+		Algorithm:  pub.Type(),
+		Encryption: encryption,
+		Comment:    comment,
+		Public:     pub.Marshal(),
+		Private:    priv,
+	}))
+
+	var buf bytes.Buffer
+
+	_, _ = fmt.Fprintf(&buf, "PuTTY-User-Key-File-3: %s\n", pub.Type())
+	_, _ = fmt.Fprintf(&buf, "Encryption: %s\n", encryption)
+	_, _ = fmt.Fprintf(&buf, "Comment: %s\n", comment)
+	writePuTTYLines(&buf, "Public-Lines", pub.Marshal())
+
+	if derived != nil {
+		_, _ = fmt.Fprintf(&buf, "Key-Derivation: Argon2id\n")
+		_, _ = fmt.Fprintf(&buf, "Argon2-Memory: %d\n", PuTTYArgon2Memory)
+		_, _ = fmt.Fprintf(&buf, "Argon2-Passes: %d\n", PuTTYArgon2Passes)
+		_, _ = fmt.Fprintf(&buf, "Argon2-Parallelism: %d\n", PuTTYArgon2Parallelism)
+		_, _ = fmt.Fprintf(&buf, "Argon2-Salt: %s\n", hex.EncodeToString(salt))
+
+		block, err := aes.NewCipher(derived[:32])
+		if err != nil {
+			return nil, err
+		}
+
+		cipher.NewCBCEncrypter(block, derived[32:32+aes.BlockSize]).CryptBlocks(priv, priv)
+	}
+
+	writePuTTYLines(&buf, "Private-Lines", priv)
+	_, _ = fmt.Fprintf(&buf, "Private-MAC: %s\n", hex.EncodeToString(mac.Sum(nil)))
+
+	return buf.Bytes(), nil
+}
+
+func marshalPuTTYPrivateBlob(key any) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("unable to encode multi-prime RSA key")
+		}
+
+		p, q := k.Primes[0], k.Primes[1]
+		blob := struct {
+			D    *big.Int
+			P    *big.Int
+			Q    *big.Int
+			IQMP *big.Int
+		}{k.D, p, q, new(big.Int).ModInverse(q, p)}
+
+		return ssh.Marshal(blob), nil
+	case *ecdsa.PrivateKey:
+		d, err := k.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		blob := struct {
+			D *big.Int
+		}{new(big.Int).SetBytes(d)}
+
+		return ssh.Marshal(blob), nil
+	case ed25519.PrivateKey:
+		blob := struct {
+			Seed []byte
+		}{k.Seed()}
+
+		return ssh.Marshal(blob), nil
+	default:
+		return nil, fmt.Errorf("unable to encode PuTTY private key of type %T", key)
+	}
+}
+
+func writePuTTYLines(w io.Writer, header string, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	lines := (len(encoded) + 63) / 64
+
+	_, _ = io.WriteString(w, header+": "+strconv.Itoa(lines)+"\n")
+
+	for len(encoded) > 64 {
+		_, _ = io.WriteString(w, encoded[:64]+"\n")
+		encoded = encoded[64:]
+	}
+
+	_, _ = io.WriteString(w, encoded+"\n")
+}
@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	jwkecdsa "github.com/lestrrat-go/jwx/v3/jwk/ecdsa"
+	"github.com/lestrrat-go/jwx/v3/jws"
+)
+
+const secp256k1Size = 32
+
+var (
+	ErrInvalidES256KSignature = errors.New("invalid ES256K signature")
+
+	secp256k1Curve = jwa.NewEllipticCurveAlgorithm("secp256k1")
+)
+
+// jwx only provides ES256K behind the jwx_es256k build tag,
+// so consumers register our own implementation explicitly
+var RegisterES256K = sync.OnceValue(func() error {
+	jwa.RegisterEllipticCurveAlgorithm(secp256k1Curve)
+	jwkecdsa.RegisterCurve(secp256k1Curve, secp256k1.S256())
+	jws.RegisterAlgorithmForCurve(secp256k1Curve, jwa.ES256K())
+
+	if err := jws.RegisterSigner(jwa.ES256K(), es256k{}); err != nil {
+		return err
+	}
+
+	return jws.RegisterVerifier(jwa.ES256K(), es256k{})
+})
+
+type es256k struct{}
+
+func (es256k) Algorithm() jwa.SignatureAlgorithm {
+	return jwa.ES256K()
+}
+
+func (es256k) Sign(key any, payload []byte) ([]byte, error) {
+	raw, err := exportES256KKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, ok := raw.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported ES256K signing key type %T", raw)
+	}
+
+	digest := sha256.Sum256(payload)
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	signature := make([]byte, 2*secp256k1Size)
+	r.FillBytes(signature[:secp256k1Size])
+	s.FillBytes(signature[secp256k1Size:])
+
+	return signature, nil
+}
+
+func (es256k) Verify(key any, payload, signature []byte) error {
+	raw, err := exportES256KKey(key)
+	if err != nil {
+		return err
+	}
+
+	var pub *ecdsa.PublicKey
+	switch k := raw.(type) {
+	case *ecdsa.PrivateKey:
+		pub = &k.PublicKey
+	case *ecdsa.PublicKey:
+		pub = k
+	default:
+		return fmt.Errorf("unsupported ES256K verification key type %T", raw)
+	}
+
+	if len(signature) != 2*secp256k1Size {
+		return ErrInvalidES256KSignature
+	}
+
+	digest := sha256.Sum256(payload)
+	r := new(big.Int).SetBytes(signature[:secp256k1Size])
+	s := new(big.Int).SetBytes(signature[secp256k1Size:])
+
+	if !ecdsa.Verify(pub, digest[:], r, s) {
+		return ErrInvalidES256KSignature
+	}
+
+	return nil
+}
+
+func exportES256KKey(key any) (any, error) {
+	k, ok := key.(jwk.Key)
+	if !ok {
+		return key, nil
+	}
+
+	var raw any
+	if err := jwk.Export(k, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type SSHCertType uint32
+
+const (
+	SSHCertTypeUser SSHCertType = ssh.UserCert
+	SSHCertTypeHost SSHCertType = ssh.HostCert
+)
+
+var sshCertTypeImpl = map[string]SSHCertType{
+	"USER": SSHCertTypeUser,
+	"HOST": SSHCertTypeHost,
+}
+
+var sshCertTypeExtensions = map[SSHCertType]map[string]string{
+	SSHCertTypeUser: {
+		"permit-X11-forwarding":   "",
+		"permit-agent-forwarding": "",
+		"permit-port-forwarding":  "",
+		"permit-pty":              "",
+		"permit-user-rc":          "",
+	},
+}
+
+func ParseSSHCertType(t string) (certType SSHCertType, err error) {
+	if t == "" {
+		certType = SSHCertTypeHost
+		return
+	}
+
+	err = (&certType).UnmarshalText([]byte(t))
+
+	return
+}
+
+func (t *SSHCertType) UnmarshalText(text []byte) error {
+	certType, ok := sshCertTypeImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid SSH certificate type %q", text)
+	}
+
+	*t = certType
+
+	return nil
+}
+
+func (t SSHCertType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t SSHCertType) String() string {
+	switch t {
+	case SSHCertTypeUser:
+		return "USER"
+	case SSHCertTypeHost:
+		return "HOST"
+	default:
+		return "unknown SSH certificate type " + strconv.FormatUint(uint64(t), 10)
+	}
+}
+
+func (t SSHCertType) Extensions() map[string]string {
+	return maps.Clone(sshCertTypeExtensions[t])
+}
@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Usage int
+
+const (
+	UsageServer Usage = 1 + iota
+	UsageClient
+	UsageServerClient
+)
+
+var usageImpl = map[string]Usage{
+	"SERVER":        UsageServer,
+	"CLIENT":        UsageClient,
+	"BOTH":          UsageServerClient,
+	"SERVER+CLIENT": UsageServerClient,
+	"CLIENT+SERVER": UsageServerClient,
+}
+
+var usageExtKeyUsages = map[Usage][]x509.ExtKeyUsage{
+	UsageServer:       {x509.ExtKeyUsageServerAuth},
+	UsageClient:       {x509.ExtKeyUsageClientAuth},
+	UsageServerClient: {x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+}
+
+func ParseUsage(u string) (usage Usage, err error) {
+	if u == "" {
+		usage = UsageServer
+		return
+	}
+
+	err = (&usage).UnmarshalText([]byte(u))
+
+	return
+}
+
+func (u *Usage) UnmarshalText(text []byte) error {
+	usage, ok := usageImpl[strings.ToUpper(string(text))]
+	if !ok {
+		return fmt.Errorf("invalid certificate usage %q", text)
+	}
+
+	*u = usage
+
+	return nil
+}
+
+func (u Usage) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+func (u Usage) String() string {
+	switch u {
+	case UsageServer:
+		return "SERVER"
+	case UsageClient:
+		return "CLIENT"
+	case UsageServerClient:
+		return "BOTH"
+	default:
+		return "unknown certificate usage " + strconv.Itoa(int(u))
+	}
+}
+
+func (u Usage) ExtKeyUsage() []x509.ExtKeyUsage {
+	return usageExtKeyUsages[u]
+}
+
+func (u Usage) Client() bool {
+	return u == UsageClient || u == UsageServerClient
+}
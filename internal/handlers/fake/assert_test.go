@@ -1,6 +1,8 @@
 package fake_test
 
 import (
+	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/assert"
@@ -24,3 +26,86 @@ func assertDTOString(field string, assertions ...assert.Assertion[string]) asser
 		}
 	}
 }
+
+func assertDTONoField(field string) assert.Assertion[DTO] {
+	return func(t *testing.T, got DTO) {
+		if dtoField, ok := got[field]; ok {
+			t.Errorf("DTO %q field: got %v, want none", field, dtoField)
+		}
+	}
+}
+
+func assertDTOField(field string) assert.Assertion[DTO] {
+	return func(t *testing.T, got DTO) {
+		if _, ok := got[field]; !ok {
+			t.Errorf("DTO %q field does not exist", field)
+		}
+	}
+}
+
+func assertDTOBool(field string, want bool) assert.Assertion[DTO] {
+	return func(t *testing.T, got DTO) {
+		dtoField, ok := got[field]
+		if !ok {
+			t.Fatalf("DTO %q field does not exist", field)
+
+			return
+		}
+
+		if dto, ok := dtoField.(bool); !ok {
+			t.Fatalf("DTO %q field is not a bool but a %T", field, dtoField)
+		} else if dto != want {
+			t.Errorf("DTO %q field: got %t, want %t", field, dto, want)
+		}
+	}
+}
+
+func assertDTOLen(field string, want int) assert.Assertion[DTO] {
+	return func(t *testing.T, got DTO) {
+		dtoField, ok := got[field]
+		if !ok {
+			t.Fatalf("DTO %q field does not exist", field)
+
+			return
+		}
+
+		if dto, ok := dtoField.([]any); !ok {
+			t.Fatalf("DTO %q field is not a list but a %T", field, dtoField)
+		} else if len(dto) != want {
+			t.Errorf("DTO %q field length: got %d, want %d", field, len(dto), want)
+		}
+	}
+}
+
+func assertDTONumber(field string, want float64) assert.Assertion[DTO] {
+	return func(t *testing.T, got DTO) {
+		dtoField, ok := got[field]
+		if !ok {
+			t.Fatalf("DTO %q field does not exist", field)
+
+			return
+		}
+
+		if dto, ok := dtoField.(float64); !ok {
+			t.Fatalf("DTO %q field is not a number but a %T", field, dtoField)
+		} else if dto != want {
+			t.Errorf("DTO %q field: got %v, want %v", field, dto, want)
+		}
+	}
+}
+
+func decodeSecret(t *testing.T, res *http.Response) string {
+	t.Helper()
+
+	var dto DTO
+	if err := json.NewDecoder(res.Body).Decode(&dto); err != nil {
+		t.Fatalf("malformed secret response: %v", err)
+	}
+
+	result, ok := dto["secret"].(string)
+	if !ok {
+		t.Fatalf("secret response has no secret: %v", dto)
+	}
+
+	return result
+}
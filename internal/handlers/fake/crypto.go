@@ -5,8 +5,14 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 	"io"
 
+	"golang.org/x/crypto/ssh"
+
 	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
 	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 )
@@ -58,3 +64,65 @@ func LoadHandlerKey(h CryptoHandler, meta *CryptoMeta, rnd io.Reader) (pub stdcr
 
 	return
 }
+
+func encodePrivateKey(rnd io.Reader, key stdcrypto.PrivateKey, format crypto.KeyFormat, passphrase, comment string) ([]byte, error) {
+	var block *pem.Block
+	var err error
+
+	switch format {
+	case crypto.KeyFormatOpenSSH:
+		if passphrase == "" {
+			block, err = ssh.MarshalPrivateKey(key, comment)
+		} else {
+			block, err = ssh.MarshalPrivateKeyWithPassphrase(key, comment, []byte(passphrase))
+		}
+	case crypto.KeyFormatPuTTY:
+		return crypto.MarshalPuTTYPrivateKey(rnd, key, comment, []byte(passphrase))
+	case crypto.KeyFormatPKCS1:
+		k, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unable to encode %T as PKCS#1 private key", key)
+		}
+
+		block = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(k),
+		}
+	case crypto.KeyFormatSEC1:
+		k, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("unable to encode %T as SEC1 private key", key)
+		}
+
+		block = &pem.Block{
+			Type: "EC PRIVATE KEY",
+		}
+		block.Bytes, err = x509.MarshalECPrivateKey(k)
+	default:
+		block = &pem.Block{
+			Type: "PRIVATE KEY",
+		}
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(key)
+		if err == nil && passphrase != "" {
+			block.Type = "ENCRYPTED PRIVATE KEY"
+			block.Bytes, err = crypto.EncryptPKCS8PrivateKey(rnd, block.Bytes, []byte(passphrase))
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase != "" && (format == crypto.KeyFormatPKCS1 || format == crypto.KeyFormatSEC1) {
+		block, err = x509.EncryptPEMBlock(rnd, block.Type, block.Bytes, []byte(passphrase), x509.PEMCipherAES256) //nolint:staticcheck
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if format == crypto.KeyFormatDER {
+		return []byte(base64.StdEncoding.EncodeToString(block.Bytes)), nil
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
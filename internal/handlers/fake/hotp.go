@@ -14,17 +14,25 @@ import (
 )
 
 type HOTPHandler struct {
-	logger *slog.Logger
-	rand   io.Reader
-	keys   cache.Cacher[*otp.Key]
+	logger   *slog.Logger
+	rand     io.Reader
+	keys     cache.Cacher[*otp.Key]
+	counters cache.Store[string, hotpCounter]
+}
+
+type hotpCounter struct {
+	token  uint64
+	server uint64
 }
 
 func NewHOTPHandler(rnd io.Reader, logger *slog.Logger) *HOTPHandler {
 	keys := cache.NewCacher[*otp.Key]()
+	counters := cache.NewStore[string, hotpCounter]()
 	result := &HOTPHandler{
-		logger: logger,
-		rand:   rnd,
-		keys:   keys,
+		logger:   logger,
+		rand:     rnd,
+		keys:     keys,
+		counters: counters,
 	}
 
 	return result
@@ -49,6 +57,7 @@ func (h *HOTPHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Reque
 		AccountName: meta.Subject,
 		SecretSize:  uint(meta.Length),
 		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
 		Random:      h.rand,
 	}
 	key, err := h.keys.Load(req)
@@ -81,6 +90,7 @@ func (h *HOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 		AccountName: meta.Subject,
 		SecretSize:  uint(meta.Length),
 		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
 		Random:      h.rand,
 	}
 	key, err := h.keys.Load(req)
@@ -89,8 +99,14 @@ func (h *HOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 		return
 	}
 
+	if meta.Counter == 0 {
+		meta.Counter = int64(h.advanceToken(key))
+	}
+
 	opts := hotp.ValidateOpts{
 		Algorithm: meta.Algorithm.OTPAlgorithm(),
+		Digits:    key.Digits(),
+		Encoder:   key.Encoder(),
 	}
 	code, err := hotp.GenerateCodeCustom(key.Secret(), uint64(meta.Counter), opts)
 	if err != nil {
@@ -102,3 +118,201 @@ func (h *HOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 
 	http.ServeSecret(w, data, meta)
 }
+
+func (h *HOTPHandler) RouteVerify(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("hotp", "{account}", "verify"), h.ServeVerify
+}
+
+func (h *HOTPHandler) ServeVerify(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseHOTPVerifyMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("verifying HOTP code", "meta", meta)
+
+	req := &cache.HOTPLoader{
+		Issuer:      meta.Organization,
+		AccountName: meta.Subject,
+		SecretSize:  uint(meta.Length),
+		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
+		Random:      h.rand,
+	}
+	key, err := h.keys.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	var data *otpVerification
+
+	_, err = h.counters.Update(key.String(), func(c hotpCounter, ok bool) (hotpCounter, error) {
+		if !ok {
+			c = hotpCounter{token: 1, server: 1}
+		}
+
+		start := max(uint64(meta.Counter), c.server)
+		data, err = verifyHOTPCode(key, meta.Algorithm.OTPAlgorithm(), meta.Code, start, meta.Window)
+		if err != nil {
+			return c, err
+		}
+
+		if data.Valid {
+			c.server = data.Counter + 1
+		}
+
+		return c, nil
+	})
+
+	serveOTPVerification(w, data, err)
+}
+
+func (h *HOTPHandler) RouteResync(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("hotp", "{account}", "resync"), h.ServeResync
+}
+
+func (h *HOTPHandler) ServeResync(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseHOTPResyncMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("resynchronizing HOTP counter", "meta", meta)
+
+	req := &cache.HOTPLoader{
+		Issuer:      meta.Organization,
+		AccountName: meta.Subject,
+		SecretSize:  uint(meta.Length),
+		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
+		Random:      h.rand,
+	}
+	key, err := h.keys.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	var data *otpVerification
+
+	_, err = h.counters.Update(key.String(), func(c hotpCounter, ok bool) (hotpCounter, error) {
+		if !ok {
+			c = hotpCounter{token: 1, server: 1}
+		}
+
+		start := max(uint64(meta.Counter), c.server)
+		data, err = resyncHOTPCode(key, meta.Algorithm.OTPAlgorithm(), meta.Code, meta.NextCode, start, meta.Window)
+		if err != nil {
+			return c, err
+		}
+
+		if data.Valid {
+			c.server = data.Counter + 1
+		}
+
+		return c, nil
+	})
+
+	serveOTPVerification(w, data, err)
+}
+
+func (h *HOTPHandler) advanceToken(key *otp.Key) uint64 {
+	c, _ := h.counters.Update(key.String(), func(c hotpCounter, ok bool) (hotpCounter, error) {
+		if !ok {
+			c = hotpCounter{token: 1, server: 1}
+		}
+
+		c.token++
+
+		return c, nil
+	})
+
+	return c.token - 1
+}
+
+func verifyHOTPCode(key *otp.Key, algorithm otp.Algorithm, code string, start, window uint64) (*otpVerification, error) {
+	opts := hotp.ValidateOpts{
+		Algorithm: algorithm,
+		Digits:    key.Digits(),
+		Encoder:   key.Encoder(),
+	}
+
+	for drift := range window + 1 {
+		counter := start + drift
+		ok, err := hotp.ValidateCustom(code, counter, key.Secret(), opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			result := &otpVerification{
+				Valid:   true,
+				Counter: counter,
+				Drift:   int64(drift),
+			}
+
+			return result, nil
+		}
+	}
+
+	return &otpVerification{}, nil
+}
+
+func resyncHOTPCode(key *otp.Key, algorithm otp.Algorithm, code, nextCode string, start, window uint64) (*otpVerification, error) {
+	for drift := uint64(0); drift <= window; drift++ {
+		first, err := verifyHOTPCode(key, algorithm, code, start+drift, window-drift)
+		if err != nil || !first.Valid {
+			return first, err
+		}
+
+		next, err := verifyHOTPCode(key, algorithm, nextCode, first.Counter+1, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		drift = first.Counter - start
+		if next.Valid {
+			next.Drift = int64(drift + 1)
+
+			return next, nil
+		}
+	}
+
+	return &otpVerification{}, nil
+}
+
+func (h *HOTPHandler) RouteQRCode(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("hotp", "{account}", "qrcodes"), h.ServeQRCode
+}
+
+func (h *HOTPHandler) ServeQRCode(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseHOTPQRCodeMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated HOTP QR code", "meta", meta)
+
+	req := &cache.HOTPLoader{
+		Issuer:      meta.Organization,
+		AccountName: meta.Subject,
+		SecretSize:  uint(meta.Length),
+		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
+		Random:      h.rand,
+	}
+	key, err := h.keys.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	serveOTPQRCode(w, key, &meta.QRCodeMeta, meta)
+}
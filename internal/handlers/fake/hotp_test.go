@@ -1,6 +1,8 @@
 package fake_test
 
 import (
+	"bytes"
+	"image/png"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -84,6 +86,48 @@ func TestHOTPHandlerServePrivateKey(t *testing.T) {
 				),
 			},
 		},
+		"digits": {
+			HaveAccount: "digits",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "8"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.OTP(
+							assert.OTPDigits(8),
+						),
+					),
+				),
+			},
+		},
+		"steam": {
+			HaveAccount: "steam",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "steam"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.OTP(
+							assert.OTPDigits(5),
+							assert.OTPEncoder("steam"),
+						),
+					),
+				),
+			},
+		},
+		"invalid_digits": {
+			HaveAccount: "digits",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "7"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
 	}
 
 	for name, test := range testCases {
@@ -111,3 +155,234 @@ func TestHOTPHandlerServePrivateKey(t *testing.T) {
 		t.Run(name, scenario)
 	}
 }
+
+func TestHOTPHandlerServeVerify(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	testCases := map[string]struct {
+		HaveCode    string
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"current": {
+			HaveRequest: []requestOption{
+				WithRequestForm("counter", "5"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+					assertDTONumber("counter", 5),
+					assertDTONumber("drift", 0),
+				),
+			},
+		},
+		"window": {
+			HaveRequest: []requestOption{
+				WithRequestForm("counter", "3"),
+				WithRequestForm("window", "2"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+					assertDTONumber("counter", 5),
+					assertDTONumber("drift", 2),
+				),
+			},
+		},
+		"outside_window": {
+			HaveRequest: []requestOption{
+				WithRequestForm("counter", "2"),
+				WithRequestForm("window", "2"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", false),
+					assertDTONoField("counter"),
+				),
+			},
+		},
+		"behind": {
+			HaveRequest: []requestOption{
+				WithRequestForm("counter", "6"),
+				WithRequestForm("window", "10"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", false),
+				),
+			},
+		},
+		"excessive_window": {
+			HaveRequest: []requestOption{
+				WithRequestForm("window", "18446744073709551615"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"length": {
+			HaveCode: "123",
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("otp-random-seed"))
+			subject := fake.NewHOTPHandler(rnd, logger)
+			req := newRequest(t.Context(),
+				WithRequestPath("hotp"),
+				WithRequestPathValue("account", "verify"),
+				WithRequestPath("codes"),
+				WithRequestQuery("counter", "5"),
+			)
+			w := httptest.NewRecorder()
+
+			subject.ServeCode(w, req)
+
+			code := test.HaveCode
+			if code == "" {
+				code = decodeSecret(t, w.Result())
+			}
+
+			reqopt := []requestOption{
+				WithRequestMethod(http.MethodPost),
+				WithRequestPath("hotp"),
+				WithRequestPathValue("account", "verify"),
+				WithRequestPath("verify"),
+				WithRequestForm("code", code),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			w = httptest.NewRecorder()
+
+			subject.ServeVerify(w, newRequest(t.Context(), reqopt...))
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestHOTPHandlerServeResync(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	rnd := io.InfiniteReader([]byte("otp-random-seed"))
+	subject := fake.NewHOTPHandler(rnd, logger)
+	code := func(counter string) string {
+		reqopt := []requestOption{
+			WithRequestPath("hotp"),
+			WithRequestPathValue("account", "resync"),
+			WithRequestPath("codes"),
+		}
+
+		if counter != "" {
+			reqopt = append(reqopt, WithRequestQuery("counter", counter))
+		}
+
+		w := httptest.NewRecorder()
+		subject.ServeCode(w, newRequest(t.Context(), reqopt...))
+
+		return decodeSecret(t, w.Result())
+	}
+	serve := func(handler http.HandlerFunc, endpoint string, form ...string) *http.Response {
+		reqopt := []requestOption{
+			WithRequestMethod(http.MethodPost),
+			WithRequestPath("hotp"),
+			WithRequestPathValue("account", "resync"),
+			WithRequestPath(endpoint),
+		}
+
+		for i := 0; i+1 < len(form); i += 2 {
+			reqopt = append(reqopt, WithRequestForm(form[i], form[i+1]))
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, newRequest(t.Context(), reqopt...))
+
+		return w.Result()
+	}
+	verify := func(form ...string) *http.Response {
+		return serve(subject.ServeVerify, "verify", form...)
+	}
+	resync := func(form ...string) *http.Response {
+		return serve(subject.ServeResync, "resync", form...)
+	}
+	accepted := func(counter, drift float64) assert.Assertions[*http.Response] {
+		return assert.Assertions[*http.Response]{
+			assert.HTTPResponseStatusCode(http.StatusOK),
+			assert.HTTPResponseBodyJSON(
+				assertDTOBool("valid", true),
+				assertDTONumber("counter", counter),
+				assertDTONumber("drift", drift),
+			),
+		}
+	}
+	rejected := assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("valid", false),
+		),
+	}
+
+	first, second, third := code(""), code(""), code("")
+	if first == second || second != code("2") || third != code("3") {
+		t.Fatalf("code sequence: got %s, %s, %s, want consecutive counters", first, second, third)
+	}
+
+	assert.Assert(t, verify("code", first), accepted(1, 0))
+	assert.Assert(t, verify("code", first), rejected)
+	assert.Assert(t, verify("code", third), rejected)
+	assert.Assert(t, verify("code", third, "window", "1"), accepted(3, 1))
+	assert.Assert(t, verify("code", second, "window", "10"), rejected)
+
+	assert.Assert(t, resync("code", code("20"), "next_code", code("22")), rejected)
+	assert.Assert(t, resync("code", code("20"), "next_code", code("21")), accepted(21, 17))
+	assert.Assert(t, verify("code", code("21")), rejected)
+	assert.Assert(t, verify("code", code("22")), accepted(22, 0))
+	assert.Assert(t, resync("code", code("30")), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+	assert.Assert(t, resync("code", code("30"), "next_code", code("31"), "window", "18446744073709551615"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+}
+
+func TestHOTPHandlerServeQRCode(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	serve := func() []byte {
+		rnd := io.InfiniteReader([]byte("otp-random-seed"))
+		subject := fake.NewHOTPHandler(rnd, logger)
+		req := newRequest(t.Context(),
+			WithRequestPath("hotp"),
+			WithRequestPathValue("account", "qrcode"),
+			WithRequestPath("qrcodes"),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeQRCode(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("invalid status code. got %d, want %d", w.Code, http.StatusOK)
+		}
+
+		return w.Body.Bytes()
+	}
+
+	first, second := serve(), serve()
+	if !bytes.Equal(first, second) {
+		t.Error("QR code rendering is not deterministic")
+	}
+
+	if _, err := png.Decode(bytes.NewReader(first)); err != nil {
+		t.Errorf("malformed PNG image: %v", err)
+	}
+}
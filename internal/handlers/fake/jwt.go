@@ -5,42 +5,71 @@ import (
 	"crypto/ed25519"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	nethttp "net/http"
 	"time"
 
+	"github.com/lestrrat-go/jwx/v3/jwe"
 	"github.com/lestrrat-go/jwx/v3/jwk"
 	"github.com/lestrrat-go/jwx/v3/jwt"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
 	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 	"github.com/UiP9AV6Y/fake-secrets/internal/http"
 )
 
+var MaxJWTClaimsSize int64 = 64 * 1024
+
 type JWTHandler struct {
-	logger  *slog.Logger
-	start   time.Time
-	rand    io.Reader
-	rsa     cache.Cacher[*rsa.PrivateKey]
-	ecdsa   cache.Cacher[*ecdsa.PrivateKey]
-	ed25519 cache.Cacher[ed25519.PrivateKey]
-	cert    cache.Cacher[*x509.Certificate]
+	logger    *slog.Logger
+	start     time.Time
+	rand      io.Reader
+	rsa       cache.Cacher[*rsa.PrivateKey]
+	ecdsa     cache.Cacher[*ecdsa.PrivateKey]
+	ed25519   cache.Cacher[ed25519.PrivateKey]
+	cert      cache.Cacher[*x509.Certificate]
+	secret    cache.Cacher[[]byte]
+	grants    cache.Store[string, *oauth2Grant]
+	codes     cache.Store[string, *oauth2Authorization]
+	keys      cache.Store[string, jwtKnownKey]
+	rotations cache.Store[string, *jwtRotation]
+	revoked   cache.Store[string, time.Time]
 }
 
 func NewJWTHandler(start time.Time, rnd io.Reader, logger *slog.Logger) *JWTHandler {
+	if err := crypto.RegisterES256K(); err != nil {
+		logger.Error("unable to register ES256K signature algorithm", "error", err)
+	}
+
 	rsa := cache.NewCacher[*rsa.PrivateKey]()
 	ecdsa := cache.NewCacher[*ecdsa.PrivateKey]()
 	ed25519 := cache.NewCacher[ed25519.PrivateKey]()
 	cert := cache.NewCacher[*x509.Certificate]()
+	secret := cache.NewCacher[[]byte]()
+	grants := cache.NewStore[string, *oauth2Grant]()
+	codes := cache.NewStore[string, *oauth2Authorization]()
+	keys := cache.NewStore[string, jwtKnownKey]()
+	rotations := cache.NewStore[string, *jwtRotation]()
+	revoked := cache.NewStore[string, time.Time]()
 	result := &JWTHandler{
-		logger:  logger,
-		start:   start,
-		rand:    rnd,
-		rsa:     rsa,
-		ecdsa:   ecdsa,
-		ed25519: ed25519,
-		cert:    cert,
+		logger:    logger,
+		start:     start,
+		rand:      rnd,
+		rsa:       rsa,
+		ecdsa:     ecdsa,
+		ed25519:   ed25519,
+		cert:      cert,
+		secret:    secret,
+		grants:    grants,
+		codes:     codes,
+		keys:      keys,
+		rotations: rotations,
+		revoked:   revoked,
 	}
 
 	return result
@@ -64,7 +93,7 @@ func (h *JWTHandler) RouteToken(cfg *config.Config) (string, nethttp.HandlerFunc
 
 func (h *JWTHandler) ServeToken(w nethttp.ResponseWriter, r *nethttp.Request) {
 	name := r.PathValue("subject")
-	meta, err := ParseJWTMeta(name, h.start, r)
+	meta, err := ParseJWTTokenMeta(name, h.start, r)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
@@ -72,27 +101,126 @@ func (h *JWTHandler) ServeToken(w nethttp.ResponseWriter, r *nethttp.Request) {
 
 	h.logger.Debug("serving generated JWT token", "meta", meta)
 
-	jti, err := generateRandomUUID(h.rand)
-	if err != nil {
+	var data []byte
+	if meta.Encryption == crypto.JWEAlgorithmNone {
+		data, err = h.signToken(&meta.JWTMeta, nil)
+	} else {
+		data, err = h.encryptToken(meta)
+	}
+
+	if errors.Is(err, ErrUnknownKeyID) {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	} else if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
-	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *JWTHandler) signToken(meta *JWTMeta, claims map[string]any) ([]byte, error) {
+	token, err := h.buildToken(meta, claims)
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		return nil, err
+	}
+
+	return h.sign(meta, token)
+}
+
+func (h *JWTHandler) sign(meta *JWTMeta, token jwt.Token) ([]byte, error) {
+	k, err := h.signingKey(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	alg := meta.SignatureAlgorithm()
+
+	return jwt.Sign(token, jwt.WithKey(alg, k))
+}
+
+func (h *JWTHandler) encryptToken(meta *JWTTokenMeta) ([]byte, error) {
+	token, err := h.buildToken(&meta.JWTMeta, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	headers := jwe.NewHeaders()
+
+	if meta.Nested {
+		payload, err = h.sign(&meta.JWTMeta, token)
+		if err != nil {
+			return nil, err
+		}
+
+		err = headers.Set(jwe.ContentTypeKey, "JWT")
+	} else {
+		payload, err = json.Marshal(token)
+		if err != nil {
+			return nil, err
+		}
+
+		err = headers.Set(jwe.TypeKey, "JWT")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := h.recipientKey(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwe.Encrypt(payload,
+		jwe.WithKey(meta.Encryption.KeyEncryptionAlgorithm(), k),
+		jwe.WithContentEncryption(meta.ContentEncryption.ContentEncryptionAlgorithm()),
+		jwe.WithProtectedHeaders(headers),
+	)
+}
+
+func (h *JWTHandler) recipientKey(meta *JWTTokenMeta) (jwk.Key, error) {
+	var key any
+
+	if meta.Encryption.Symmetric() {
+		req := &cache.SecretLoader{
+			Hostname: meta.RecipientCrypto.Subject,
+			Length:   meta.Encryption.SecretLength(),
+			Random:   h.rand,
+		}
+
+		secret, err := h.secret.Load(req)
+		if err != nil {
+			return nil, err
+		}
+
+		key = secret
+	} else {
+		pub, _, err := LoadHandlerKey(h, meta.RecipientCrypto, h.rand)
+		if err != nil {
+			return nil, err
+		}
+
+		key = pub
 	}
 
 	k, err := jwk.Import(key)
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		return nil, err
 	}
 
 	if err := jwk.AssignKeyID(k); err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (h *JWTHandler) buildToken(meta *JWTMeta, claims map[string]any) (jwt.Token, error) {
+	jti, err := generateRandomUUID(h.rand)
+	if err != nil {
+		return nil, err
 	}
 
 	b := jwt.NewBuilder().
@@ -107,20 +235,54 @@ func (h *JWTHandler) ServeToken(w nethttp.ResponseWriter, r *nethttp.Request) {
 		b.Audience([]string{meta.Audience})
 	}
 
-	token, err := b.Build()
+	for name, value := range meta.Claims {
+		b.Claim(name, value)
+	}
+
+	for name, value := range claims {
+		b.Claim(name, value)
+	}
+
+	return b.Build()
+}
+
+func (h *JWTHandler) signingKey(meta *JWTMeta) (jwk.Key, error) {
+	keys, err := h.loadKeys(meta)
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		return nil, err
 	}
 
-	alg := meta.SignatureAlgorithm()
-	data, err := jwt.Sign(token, jwt.WithKey(alg, k))
+	if meta.KeyID == "" {
+		return keys[0], nil
+	}
+
+	for _, k := range keys {
+		if kid, _ := k.KeyID(); kid == meta.KeyID {
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKeyID
+}
+
+func (h *JWTHandler) registerKey(subject string, generation int, key jwk.Key) error {
+	kid, _ := key.KeyID()
+	if _, ok := h.keys.Get(kid); ok {
+		return nil
+	}
+
+	pub, err := key.PublicKey()
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		return err
 	}
 
-	http.ServeSecret(w, data, meta)
+	h.keys.Put(kid, jwtKnownKey{
+		subject:    subject,
+		generation: generation,
+		key:        pub,
+	})
+
+	return nil
 }
 
 func (h *JWTHandler) RouteCertificate(cfg *config.Config) (string, nethttp.HandlerFunc) {
@@ -135,21 +297,44 @@ func (h *JWTHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 		return
 	}
 
+	if meta.JWSAlgorithm.Symmetric() {
+		http.ServeError(w, nethttp.StatusBadRequest, fmt.Errorf("alg %s does not use a public key", meta.JWSAlgorithm))
+		return
+	}
+
 	h.logger.Debug("serving generated JWK public keyset", "meta", meta)
 
-	key, _, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	keys, err := h.loadPublicKeys(meta)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
-	k, err := jwk.Import(key)
+	serveJWTKeySet(w, meta, keys...)
+}
+
+func (h *JWTHandler) RouteSecret(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("jwt", "{subject}", "secrets"), h.ServeSecret
+}
+
+func (h *JWTHandler) ServeSecret(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("subject")
+	meta, err := ParseJWTMeta(name, h.start, r)
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
 	}
 
-	serveJWTKeySet(w, k, meta)
+	if meta.JWSAlgorithm == crypto.JWSAlgorithmNone {
+		meta.JWSAlgorithm = crypto.JWSAlgorithmHS256
+	} else if !meta.JWSAlgorithm.Symmetric() {
+		http.ServeError(w, nethttp.StatusBadRequest, fmt.Errorf("alg %s does not use a shared secret", meta.JWSAlgorithm))
+		return
+	}
+
+	h.logger.Debug("serving generated JWK secret keyset", "meta", meta)
+
+	h.serveSigningKey(w, meta)
 }
 
 func (h *JWTHandler) RoutePrivateKey(cfg *config.Config) (string, nethttp.HandlerFunc) {
@@ -164,34 +349,50 @@ func (h *JWTHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Reques
 		return
 	}
 
+	if meta.JWSAlgorithm.Symmetric() {
+		http.ServeError(w, nethttp.StatusBadRequest, fmt.Errorf("alg %s does not use a private key", meta.JWSAlgorithm))
+		return
+	}
+
 	h.logger.Debug("serving generated JWK private keyset", "meta", meta)
 
-	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
-	if err != nil {
+	h.serveSigningKey(w, meta)
+}
+
+func (h *JWTHandler) serveSigningKey(w nethttp.ResponseWriter, meta *JWTMeta) {
+	key, err := h.signingKey(meta)
+	if errors.Is(err, ErrUnknownKeyID) {
+		http.ServeError(w, nethttp.StatusNotFound, err)
+		return
+	} else if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
-	k, err := jwk.Import(key)
+	serveJWTKeySet(w, meta, key)
+}
+
+func serveJWTKeySet(w nethttp.ResponseWriter, meta any, keys ...jwk.Key) {
+	data, err := newJWTKeySet(keys...)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
-	serveJWTKeySet(w, k, meta)
+	http.ServeSecretObject(w, data, meta)
 }
 
-func serveJWTKeySet(w nethttp.ResponseWriter, key jwk.Key, meta any) {
-	if err := jwk.AssignKeyID(key); err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
-	}
+func newJWTKeySet(keys ...jwk.Key) (jwk.Set, error) {
+	result := jwk.NewSet()
+	for _, k := range keys {
+		if err := jwk.AssignKeyID(k); err != nil {
+			return nil, err
+		}
 
-	data := jwk.NewSet()
-	if err := data.AddKey(key); err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
-		return
+		if err := result.AddKey(k); err != nil {
+			return nil, err
+		}
 	}
 
-	http.ServeSecretObject(w, data, meta)
+	return result, nil
 }
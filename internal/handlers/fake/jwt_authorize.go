@@ -0,0 +1,256 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	nethttp "net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+const (
+	OAuth2ResponseTypeCode     = "code"
+	OAuth2ChallengeMethodS256  = "S256"
+	OAuth2DefaultResourceOwner = "user"
+)
+
+const ContentTypeHTML = "text/html; charset=utf-8"
+
+var OAuth2AuthorizationCodeLifetime = 10 * time.Minute
+
+var (
+	ErrCodeVerifierMismatch       = errors.New("code_verifier does not match the code_challenge")
+	ErrInvalidRedirectURI         = errors.New("redirect_uri must be an absolute URL")
+	ErrNoCodeVerifier             = errors.New("code_verifier is required")
+	ErrUnexpectedCodeVerifier     = errors.New("code_verifier was sent without a code_challenge")
+	ErrUnsupportedChallengeMethod = errors.New("code_challenge_method must be S256")
+	ErrUnsupportedResponseType    = errors.New("response_type must be code")
+)
+
+var oauth2PickerTemplate = template.Must(template.New("picker").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{ .Issuer }}</title></head>
+<body>
+<form method="get">
+{{- range $name, $values := .Params }}{{ range $values }}
+<input type="hidden" name="{{ $name }}" value="{{ . }}">
+{{- end }}{{ end }}
+<label>Sign in to {{ .ClientID }} as <input type="text" name="login_hint" value="{{ .Subject }}" autofocus></label>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+type oauth2Authorization struct {
+	grant       *oauth2Grant
+	redirectURI string
+	challenge   string
+	expires     time.Time
+}
+
+func (a *oauth2Authorization) valid(issuer, clientID, redirectURI string, now time.Time) bool {
+	if a.grant.issuer != issuer || a.redirectURI != redirectURI || !now.Before(a.expires) {
+		return false
+	}
+
+	// RFC 6749, section 4.1.3: the client must identify itself
+	return clientID != "" && clientID == a.grant.clientID
+}
+
+func (a *oauth2Authorization) verifyChallenge(verifier string) error {
+	if a.challenge == "" {
+		if verifier != "" {
+			return ErrUnexpectedCodeVerifier
+		}
+
+		return nil
+	}
+
+	if verifier == "" {
+		return ErrNoCodeVerifier
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	want := base64.RawURLEncoding.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(want), []byte(a.challenge)) != 1 {
+		return ErrCodeVerifierMismatch
+	}
+
+	return nil
+}
+
+func (h *JWTHandler) RouteOAuth2Authorize(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("oauth2", "{issuer}", "authorize"), h.ServeOAuth2Authorize
+}
+
+func (h *JWTHandler) ServeOAuth2Authorize(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseOAuth2AuthorizeMeta(r.PathValue("issuer"), time.Now(), r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, 1)
+
+	h.logger.Debug("serving OAuth2 authorization", "meta", meta)
+
+	if meta.ResponseType != OAuth2ResponseTypeCode {
+		redirectOAuth2(w, r, meta, url.Values{
+			"error":             {"unsupported_response_type"},
+			"error_description": {ErrUnsupportedResponseType.Error()},
+		})
+		return
+	}
+
+	if meta.CodeChallenge != "" && meta.CodeChallengeMethod != OAuth2ChallengeMethodS256 {
+		redirectOAuth2(w, r, meta, url.Values{
+			"error":             {"invalid_request"},
+			"error_description": {ErrUnsupportedChallengeMethod.Error()},
+		})
+		return
+	}
+
+	if meta.LoginHint == "" && slices.Contains(strings.Fields(meta.Prompt), "select_account") {
+		serveOAuth2Picker(w, r, meta)
+		return
+	}
+
+	subject := meta.LoginHint
+	if subject == "" {
+		subject = OAuth2DefaultResourceOwner
+	}
+
+	code, err := generateRandomUUID(h.rand)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	auth := &oauth2Authorization{
+		grant: &oauth2Grant{
+			issuer:   meta.IssuerClaim(),
+			subject:  subject,
+			clientID: meta.ClientID,
+			scope:    meta.Scope,
+			nonce:    meta.Nonce,
+			identity: true,
+			claims:   meta.Claims,
+		},
+		redirectURI: meta.RedirectURI,
+		challenge:   meta.CodeChallenge,
+		expires:     now.Add(OAuth2AuthorizationCodeLifetime),
+	}
+
+	h.codes.DeleteFunc(func(_ string, a *oauth2Authorization) bool {
+		return !now.Before(a.expires)
+	})
+	h.codes.Put(string(code), auth)
+
+	redirectOAuth2(w, r, meta, url.Values{
+		"code": {string(code)},
+	})
+}
+
+func redirectOAuth2(w nethttp.ResponseWriter, r *nethttp.Request, meta *OAuth2AuthorizeMeta, params url.Values) {
+	target, err := url.Parse(meta.RedirectURI)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	if meta.State != "" {
+		params.Set("state", meta.State)
+	}
+
+	query := target.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+
+	target.RawQuery = query.Encode()
+
+	nethttp.Redirect(w, r, target.String(), nethttp.StatusFound)
+}
+
+func serveOAuth2Picker(w nethttp.ResponseWriter, r *nethttp.Request, meta *OAuth2AuthorizeMeta) {
+	params := url.Values{}
+	for name, values := range r.Form {
+		if name != "prompt" && name != "login_hint" {
+			params[name] = values
+		}
+	}
+
+	data := map[string]any{
+		"Issuer":   meta.IssuerClaim(),
+		"ClientID": meta.ClientID,
+		"Subject":  OAuth2DefaultResourceOwner,
+		"Params":   params,
+	}
+
+	var buf bytes.Buffer
+	if err := oauth2PickerTemplate.Execute(&buf, data); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	http.ServeBlob(w, ContentTypeHTML, buf.Bytes())
+}
+
+func (h *JWTHandler) RouteOAuth2UserInfo(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("oauth2", "{issuer}", "userinfo"), h.ServeOAuth2UserInfo
+}
+
+func (h *JWTHandler) ServeOAuth2UserInfo(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := h.parseIssuerMeta(r, 1)
+	if err != nil {
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	h.logger.Debug("serving OpenID Connect userinfo", "meta", meta)
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		bearer = http.ParseFormString(r, "access_token", "")
+	}
+
+	if bearer == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+meta.IssuerClaim()+`"`)
+		serveOAuth2Error(w, nethttp.StatusUnauthorized, "invalid_request", ErrNoToken)
+		return
+	}
+
+	token, err := h.verifyToken(bearer, meta.IssuerClaim(), time.Now())
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+meta.IssuerClaim()+`", error="invalid_token"`)
+		serveOAuth2Error(w, nethttp.StatusUnauthorized, "invalid_token", err)
+		return
+	}
+
+	data := map[string]any{}
+	for _, name := range token.Keys() {
+		switch name {
+		case "iss", "aud", "exp", "nbf", "iat", "jti", "scope", "client_id":
+			continue
+		}
+
+		var value any
+		if err := token.Get(name, &value); err == nil {
+			data[name] = value
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeJSON(w, data)
+}
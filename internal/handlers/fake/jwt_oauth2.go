@@ -0,0 +1,409 @@
+package fake
+
+import (
+	"errors"
+	"maps"
+	nethttp "net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+const (
+	OAuth2GrantAuthorizationCode = "authorization_code"
+	OAuth2GrantClientCredentials = "client_credentials"
+	OAuth2GrantPassword          = "password"
+	OAuth2GrantRefreshToken      = "refresh_token"
+)
+
+var (
+	ErrNoAuthorizationCode  = errors.New("code is required")
+	ErrNoClientID           = errors.New("client_id is required")
+	ErrNoResourceOwner      = errors.New("username and password are required")
+	ErrNoRefreshToken       = errors.New("refresh_token is required")
+	ErrNoToken              = errors.New("token is required")
+	ErrInvalidScope         = errors.New("scope exceeds the originally granted scope")
+	ErrRevokedToken         = errors.New("token has been revoked")
+	ErrUnknownCode          = errors.New("authorization code is invalid, expired or has been used already")
+	ErrUnknownRefreshToken  = errors.New("refresh token is invalid or has been used already")
+	ErrUnsupportedGrantType = errors.New("grant_type is not supported")
+)
+
+type oauth2Grant struct {
+	issuer   string
+	subject  string
+	clientID string
+	scope    string
+	nonce    string
+	identity bool
+	claims   map[string]any
+}
+
+type oauth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type oauth2Introspection struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	JwtID     string   `json:"jti,omitempty"`
+	Expires   int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+type oauth2Error struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (h *JWTHandler) RouteOAuth2Token(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("oauth2", "{issuer}", "token"), h.ServeOAuth2Token
+}
+
+func (h *JWTHandler) ServeOAuth2Token(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseOAuth2TokenMeta(r.PathValue("issuer"), time.Now(), r)
+	if err != nil {
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, 1)
+
+	h.logger.Debug("serving OAuth2 access token", "meta", meta)
+
+	grant := &oauth2Grant{
+		issuer:   meta.IssuerClaim(),
+		subject:  meta.ClientID,
+		clientID: meta.ClientID,
+		scope:    meta.Scope,
+	}
+	refresh := true
+
+	switch meta.GrantType {
+	case OAuth2GrantAuthorizationCode:
+		if meta.Code == "" {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", ErrNoAuthorizationCode)
+			return
+		}
+
+		auth, ok := h.codes.Get(meta.Code)
+		h.codes.Delete(meta.Code)
+
+		if !ok || !auth.valid(grant.issuer, meta.ClientID, meta.RedirectURI, time.Now()) {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_grant", ErrUnknownCode)
+			return
+		}
+
+		if err := auth.verifyChallenge(meta.CodeVerifier); err != nil {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_grant", err)
+			return
+		}
+
+		grant = auth.grant
+	case OAuth2GrantClientCredentials:
+		if meta.ClientID == "" {
+			serveOAuth2Error(w, nethttp.StatusUnauthorized, "invalid_client", ErrNoClientID)
+			return
+		}
+
+		refresh = false
+	case OAuth2GrantPassword:
+		if meta.Username == "" || meta.Password == "" {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", ErrNoResourceOwner)
+			return
+		}
+
+		grant.subject = meta.Username
+	case OAuth2GrantRefreshToken:
+		if meta.RefreshToken == "" {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", ErrNoRefreshToken)
+			return
+		}
+
+		previous, ok := h.grants.Get(meta.RefreshToken)
+		if !ok || previous.issuer != grant.issuer || (meta.ClientID != "" && previous.clientID != meta.ClientID) {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_grant", ErrUnknownRefreshToken)
+			return
+		}
+
+		// RFC 6749, section 6: the scope can only be narrowed down
+		if !isScopeSubset(grant.scope, previous.scope) {
+			serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_scope", ErrInvalidScope)
+			return
+		}
+
+		h.grants.Delete(meta.RefreshToken)
+
+		grant.subject = previous.subject
+		grant.clientID = previous.clientID
+		grant.identity = previous.identity
+		grant.claims = previous.claims
+		if grant.scope == "" {
+			grant.scope = previous.scope
+		}
+	default:
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "unsupported_grant_type", ErrUnsupportedGrantType)
+		return
+	}
+
+	h.serveOAuth2Token(w, &meta.JWTMeta, grant, refresh)
+}
+
+func (h *JWTHandler) serveOAuth2Token(w nethttp.ResponseWriter, meta *JWTMeta, grant *oauth2Grant, refresh bool) {
+	claims := maps.Clone(grant.claims)
+	if claims == nil {
+		claims = map[string]any{}
+	}
+
+	claims["sub"] = grant.subject
+
+	if grant.clientID != "" {
+		claims["client_id"] = grant.clientID
+	}
+
+	if grant.scope != "" {
+		claims["scope"] = grant.scope
+	}
+
+	access, err := h.signToken(meta, claims)
+	if errors.Is(err, ErrUnknownKeyID) {
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", err)
+		return
+	} else if err != nil {
+		serveOAuth2Error(w, nethttp.StatusInternalServerError, "server_error", err)
+		return
+	}
+
+	data := &oauth2Token{
+		AccessToken: string(access),
+		TokenType:   "Bearer",
+		ExpiresIn:   meta.ValidAt + meta.ValidFor - meta.IssuedAt,
+		Scope:       grant.scope,
+	}
+
+	if grant.identity {
+		identity, err := h.signIDToken(meta, grant)
+		if err != nil {
+			serveOAuth2Error(w, nethttp.StatusInternalServerError, "server_error", err)
+			return
+		}
+
+		data.IDToken = string(identity)
+	}
+
+	if refresh {
+		token, err := generateRandomUUID(h.rand)
+		if err != nil {
+			serveOAuth2Error(w, nethttp.StatusInternalServerError, "server_error", err)
+			return
+		}
+
+		next := *grant
+		next.nonce = ""
+
+		data.RefreshToken = string(token)
+		h.grants.Put(data.RefreshToken, &next)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeJSON(w, data)
+}
+
+func (h *JWTHandler) signIDToken(meta *JWTMeta, grant *oauth2Grant) ([]byte, error) {
+	claims := maps.Clone(grant.claims)
+	if claims == nil {
+		claims = map[string]any{}
+	}
+
+	claims["sub"] = grant.subject
+	claims["azp"] = grant.clientID
+
+	if grant.nonce != "" {
+		claims["nonce"] = grant.nonce
+	}
+
+	identity := *meta
+	identity.Audience = grant.clientID
+
+	return h.signToken(&identity, claims)
+}
+
+func isScopeSubset(scope, granted string) bool {
+	available := strings.Fields(granted)
+	for _, s := range strings.Fields(scope) {
+		if !slices.Contains(available, s) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func serveOAuth2Error(w nethttp.ResponseWriter, code int, reason string, err error) {
+	dto := &oauth2Error{
+		Error:       reason,
+		Description: err.Error(),
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	http.ServeJSON(w, dto)
+}
+
+func (h *JWTHandler) RouteOAuth2Introspection(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("oauth2", "{issuer}", "introspect"), h.ServeOAuth2Introspection
+}
+
+func (h *JWTHandler) ServeOAuth2Introspection(w nethttp.ResponseWriter, r *nethttp.Request) {
+	now := time.Now()
+	meta, err := ParseOAuth2TokenReferenceMeta(r.PathValue("issuer"), now, r)
+	if err != nil {
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, 1)
+
+	h.logger.Debug("serving OAuth2 token introspection", "meta", meta)
+
+	// RFC 7662, section 2.1: the hint is advisory only, so fall back
+	// to the other token type if the hinted lookup misses
+	var data *oauth2Introspection
+	if meta.TokenTypeHint == "access_token" {
+		data = h.introspectAccessToken(meta, now)
+		if !data.Active {
+			data = h.introspectRefreshToken(meta)
+		}
+	} else {
+		data = h.introspectRefreshToken(meta)
+		if !data.Active {
+			data = h.introspectAccessToken(meta, now)
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeJSON(w, data)
+}
+
+func (h *JWTHandler) introspectRefreshToken(meta *OAuth2TokenReferenceMeta) *oauth2Introspection {
+	grant, ok := h.grants.Get(meta.Token)
+	if !ok || grant.issuer != meta.IssuerClaim() {
+		return &oauth2Introspection{}
+	}
+
+	result := &oauth2Introspection{
+		Active:    true,
+		TokenType: OAuth2GrantRefreshToken,
+		Subject:   grant.subject,
+		ClientID:  grant.clientID,
+		Scope:     grant.scope,
+		Issuer:    grant.issuer,
+	}
+
+	return result
+}
+
+func (h *JWTHandler) introspectAccessToken(meta *OAuth2TokenReferenceMeta, now time.Time) *oauth2Introspection {
+	result := &oauth2Introspection{}
+
+	token, err := h.verifyToken(meta.Token, meta.IssuerClaim(), now)
+	if err != nil {
+		h.logger.Debug("inactive OAuth2 token", "error", err)
+		return result
+	}
+
+	result.Active = true
+	result.TokenType = "Bearer"
+	result.Subject, _ = token.Subject()
+	result.Issuer, _ = token.Issuer()
+	result.Audience, _ = token.Audience()
+	result.JwtID, _ = token.JwtID()
+	_ = token.Get("scope", &result.Scope)
+	_ = token.Get("client_id", &result.ClientID)
+
+	if exp, ok := token.Expiration(); ok {
+		result.Expires = exp.Unix()
+	}
+
+	if nbf, ok := token.NotBefore(); ok {
+		result.NotBefore = nbf.Unix()
+	}
+
+	if iat, ok := token.IssuedAt(); ok {
+		result.IssuedAt = iat.Unix()
+	}
+
+	return result
+}
+
+func (h *JWTHandler) RouteOAuth2Revocation(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("oauth2", "{issuer}", "revoke"), h.ServeOAuth2Revocation
+}
+
+func (h *JWTHandler) ServeOAuth2Revocation(w nethttp.ResponseWriter, r *nethttp.Request) {
+	now := time.Now()
+	meta, err := ParseOAuth2TokenReferenceMeta(r.PathValue("issuer"), now, r)
+	if err != nil {
+		serveOAuth2Error(w, nethttp.StatusBadRequest, "invalid_request", err)
+		return
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, 1)
+
+	h.logger.Debug("revoking OAuth2 token", "meta", meta)
+
+	if grant, ok := h.grants.Get(meta.Token); ok {
+		if grant.issuer == meta.IssuerClaim() {
+			h.grants.Delete(meta.Token)
+		}
+	} else if token, err := h.verifyToken(meta.Token, meta.IssuerClaim(), now); err == nil {
+		if jti, ok := token.JwtID(); ok {
+			exp, _ := token.Expiration()
+			h.revoked.Put(jti, exp)
+		}
+	}
+
+	// invalid tokens do not cause an error response (RFC 7009, section 2.2)
+	w.WriteHeader(nethttp.StatusOK)
+}
+
+func (h *JWTHandler) verifyToken(token, issuer string, now time.Time) (jwt.Token, error) {
+	set, err := newJWTKeySet(h.verificationKeys(now)...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := jwt.ParseString(token,
+		jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithIssuer(issuer),
+		jwt.WithClock(jwt.ClockFunc(func() time.Time { return now })),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if jti, ok := result.JwtID(); ok {
+		if _, revoked := h.revoked.Get(jti); revoked {
+			return nil, ErrRevokedToken
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package fake
+
+import (
+	"fmt"
+	nethttp "net/http"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+type oidcConfiguration struct {
+	Issuer                           string   `json:"issuer"`
+	JWKSURI                          string   `json:"jwks_uri"`
+	AuthorizationEndpoint            string   `json:"authorization_endpoint"`
+	TokenEndpoint                    string   `json:"token_endpoint"`
+	UserInfoEndpoint                 string   `json:"userinfo_endpoint"`
+	GrantTypesSupported              []string `json:"grant_types_supported"`
+	TokenEndpointAuthMethods         []string `json:"token_endpoint_auth_methods_supported"`
+	IntrospectionEndpoint            string   `json:"introspection_endpoint"`
+	RevocationEndpoint               string   `json:"revocation_endpoint"`
+	ResponseTypesSupported           []string `json:"response_types_supported"`
+	CodeChallengeMethodsSupported    []string `json:"code_challenge_methods_supported"`
+	SubjectTypesSupported            []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported []string `json:"id_token_signing_alg_values_supported"`
+	ClaimsSupported                  []string `json:"claims_supported"`
+}
+
+func (h *JWTHandler) RouteDiscovery(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("oauth2", "{issuer}", ".well-known", "openid-configuration"), h.ServeDiscovery
+}
+
+func (h *JWTHandler) ServeDiscovery(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := h.parseIssuerMeta(r, 2)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving OpenID Connect discovery document", "meta", meta)
+
+	data := &oidcConfiguration{
+		Issuer:                           meta.IssuerClaim(),
+		JWKSURI:                          issuerEndpoint(meta, r, "jwks"),
+		AuthorizationEndpoint:            issuerEndpoint(meta, r, "authorize"),
+		TokenEndpoint:                    issuerEndpoint(meta, r, "token"),
+		UserInfoEndpoint:                 issuerEndpoint(meta, r, "userinfo"),
+		GrantTypesSupported:              []string{OAuth2GrantAuthorizationCode, OAuth2GrantClientCredentials, OAuth2GrantPassword, OAuth2GrantRefreshToken},
+		TokenEndpointAuthMethods:         []string{"client_secret_basic", "client_secret_post"},
+		IntrospectionEndpoint:            issuerEndpoint(meta, r, "introspect"),
+		RevocationEndpoint:               issuerEndpoint(meta, r, "revoke"),
+		ResponseTypesSupported:           []string{OAuth2ResponseTypeCode},
+		CodeChallengeMethodsSupported:    []string{OAuth2ChallengeMethodS256},
+		SubjectTypesSupported:            []string{"public"},
+		IDTokenSigningAlgValuesSupported: []string{meta.SignatureAlgorithm().String()},
+		ClaimsSupported:                  []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "nonce", "azp"},
+	}
+
+	http.ServeJSON(w, data)
+}
+
+func (h *JWTHandler) RouteJWKS(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("oauth2", "{issuer}", "jwks"), h.ServeJWKS
+}
+
+func (h *JWTHandler) ServeJWKS(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := h.parseIssuerMeta(r, 1)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	if meta.JWSAlgorithm.Symmetric() {
+		http.ServeError(w, nethttp.StatusBadRequest, fmt.Errorf("alg %s does not use a public key", meta.JWSAlgorithm))
+		return
+	}
+
+	h.logger.Debug("serving raw JWK public keyset", "meta", meta)
+
+	keys, err := h.loadPublicKeys(meta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data, err := newJWTKeySet(keys...)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	http.ServeJSON(w, data)
+}
+
+func (h *JWTHandler) parseIssuerMeta(r *nethttp.Request, trim int) (*JWTMeta, error) {
+	meta, err := ParseJWTMeta(r.PathValue("issuer"), h.start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, trim)
+
+	return meta, nil
+}
+
+func issuerEndpoint(meta *JWTMeta, r *nethttp.Request, endpoint string) string {
+	result := meta.IssuerClaim() + "/" + endpoint
+	if r.URL.RawQuery != "" {
+		result += "?" + r.URL.RawQuery
+	}
+
+	return result
+}
@@ -0,0 +1,221 @@
+package fake
+
+import (
+	"errors"
+	nethttp "net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+var (
+	ErrUnknownKeyID = errors.New("no key with the requested kid is available")
+
+	MaxJWTGracePeriod uint64 = 60 * 60 * 24 * 365 // 1 year
+)
+
+type jwtRotation struct {
+	current int
+	retired []jwtRetiredKey
+}
+
+type jwtRetiredKey struct {
+	generation int
+	expires    time.Time
+}
+
+type jwtKnownKey struct {
+	subject    string
+	generation int
+	key        jwk.Key
+}
+
+func (h *JWTHandler) RouteRotation(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("jwt", "{subject}", "rotate"), h.ServeRotation
+}
+
+func (h *JWTHandler) ServeRotation(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseJWTRotationMeta(r.PathValue("subject"), h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.serveRotation(w, meta)
+}
+
+func (h *JWTHandler) RouteIssuerRotation(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("oauth2", "{issuer}", "rotate"), h.ServeIssuerRotation
+}
+
+func (h *JWTHandler) ServeIssuerRotation(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseJWTRotationMeta(r.PathValue("issuer"), h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	meta.Organization = http.ParseHeaderRouteURL(r, 1)
+
+	h.serveRotation(w, meta)
+}
+
+func (h *JWTHandler) serveRotation(w nethttp.ResponseWriter, meta *JWTRotationMeta) {
+	h.logger.Debug("rotating JWT signing keys", "meta", meta)
+
+	h.rotate(meta.Subject, time.Now(), meta.GracePeriodDuration())
+
+	meta.KeyID = ""
+	key, err := h.signingKey(&meta.JWTMeta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if !meta.JWSAlgorithm.Symmetric() {
+		key, err = key.PublicKey()
+		if err != nil {
+			http.ServeError(w, nethttp.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	serveJWTKeySet(w, meta, key)
+}
+
+func (h *JWTHandler) rotate(subject string, now time.Time, grace time.Duration) {
+	_, _ = h.rotations.Update(subject, func(rot *jwtRotation, ok bool) (*jwtRotation, error) {
+		result := &jwtRotation{}
+		if ok {
+			result.current = rot.current
+			result.retired = slices.DeleteFunc(slices.Clone(rot.retired), func(k jwtRetiredKey) bool {
+				return !now.Before(k.expires)
+			})
+		}
+
+		if grace > 0 {
+			result.retired = append(result.retired, jwtRetiredKey{
+				generation: result.current,
+				expires:    now.Add(grace),
+			})
+		}
+
+		result.current++
+
+		return result, nil
+	})
+}
+
+func (h *JWTHandler) generations(subject string, now time.Time) []int {
+	rot, ok := h.rotations.Get(subject)
+	if !ok {
+		return []int{0}
+	}
+
+	result := []int{rot.current}
+	for _, k := range slices.Backward(rot.retired) {
+		if now.Before(k.expires) {
+			result = append(result, k.generation)
+		}
+	}
+
+	return result
+}
+
+// verificationKeys only includes keys of generations which have
+// neither been rotated out nor exceeded their grace period
+func (h *JWTHandler) verificationKeys(now time.Time) []jwk.Key {
+	known := h.keys.Values()
+	result := make([]jwk.Key, 0, len(known))
+
+	for _, k := range known {
+		if slices.Contains(h.generations(k.subject, now), k.generation) {
+			result = append(result, k.key)
+		}
+	}
+
+	return result
+}
+
+func (h *JWTHandler) loadKeys(meta *JWTMeta) ([]jwk.Key, error) {
+	generations := h.generations(meta.Subject, time.Now())
+	result := make([]jwk.Key, 0, len(generations))
+
+	for _, generation := range generations {
+		var key any
+
+		subject := generationSubject(meta.Subject, generation)
+
+		if meta.JWSAlgorithm.Symmetric() {
+			req := &cache.SecretLoader{
+				Hostname: subject,
+				Length:   meta.JWSAlgorithm.SecretLength(),
+				Random:   h.rand,
+			}
+
+			secret, err := h.secret.Load(req)
+			if err != nil {
+				return nil, err
+			}
+
+			key = secret
+		} else {
+			crypt := meta.CryptoMeta
+			crypt.Subject = subject
+
+			_, priv, err := LoadHandlerKey(h, &crypt, h.rand)
+			if err != nil {
+				return nil, err
+			}
+
+			key = priv
+		}
+
+		k, err := jwk.Import(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := jwk.AssignKeyID(k); err != nil {
+			return nil, err
+		}
+
+		if err := h.registerKey(meta.Subject, generation, k); err != nil {
+			return nil, err
+		}
+
+		result = append(result, k)
+	}
+
+	return result, nil
+}
+
+func (h *JWTHandler) loadPublicKeys(meta *JWTMeta) ([]jwk.Key, error) {
+	keys, err := h.loadKeys(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]jwk.Key, len(keys))
+	for i, k := range keys {
+		if result[i], err = k.PublicKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func generationSubject(subject string, generation int) string {
+	if generation == 0 {
+		return subject
+	}
+
+	return subject + "#" + strconv.Itoa(generation)
+}
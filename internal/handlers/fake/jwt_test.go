@@ -1,12 +1,23 @@
 package fake_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"log/slog"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwe"
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+
 	"github.com/UiP9AV6Y/fake-secrets/internal/assert"
 	"github.com/UiP9AV6Y/fake-secrets/internal/handlers/fake"
 	"github.com/UiP9AV6Y/fake-secrets/internal/io"
@@ -105,6 +116,147 @@ func TestJWTHandlerServeToken(t *testing.T) {
 				),
 			},
 		},
+		"alg_ps512": {
+			HaveSubject: "alg_ps512",
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "PS512"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWSAlgorithm("PS512"),
+					),
+				),
+			},
+		},
+		"alg_es256k": {
+			HaveSubject: "alg_es256k",
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "es256k"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWSAlgorithm("ES256K"),
+					),
+				),
+			},
+		},
+		"curve_secp256k1": {
+			HaveSubject: "curve_secp256k1",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("curve", "secp256k1"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWSAlgorithm("ES256K"),
+					),
+				),
+			},
+		},
+		"alg_hs384": {
+			HaveSubject: "alg_hs384",
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "HS384"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWSAlgorithm("HS384"),
+					),
+				),
+			},
+		},
+		"alg_invalid": {
+			HaveSubject: "alg_invalid",
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "none"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"alg_algorithm_mismatch": {
+			HaveSubject: "alg_algorithm_mismatch",
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "RS256"),
+				WithRequestQuery("algorithm", "ed25519"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"claims_form": {
+			HaveSubject: "claims_form",
+			HaveRequest: []requestOption{
+				WithRequestQuery("claim.email", "jdoe@example.test"),
+				WithRequestQuery("claim.roles", "admin"),
+				WithRequestQuery("claim.roles", "user"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWT(
+							assert.JWTClaim("email",
+								assert.StringEqual(`"jdoe@example.test"`),
+							),
+							assert.JWTClaim("roles",
+								assert.StringEqual(`["admin","user"]`),
+							),
+							assert.JWTSubject(
+								assert.StringEqual("claims_form"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"claims_json": {
+			HaveSubject: "claims_json",
+			HaveRequest: []requestOption{
+				WithRequestBody("application/json", []byte(`{"tenant":{"id":42,"groups":["ops"]},"sub":"override"}`)),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.JWT(
+							assert.JWTClaim("tenant",
+								assert.StringEqual(`{"groups":["ops"],"id":42}`),
+							),
+							assert.JWTSubject(
+								assert.StringEqual("override"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"claims_json_malformed": {
+			HaveSubject: "claims_json_malformed",
+			HaveRequest: []requestOption{
+				WithRequestBody("application/json", []byte(`["scope"]`)),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"claims_invalid": {
+			HaveSubject: "claims_invalid",
+			HaveRequest: []requestOption{
+				WithRequestQuery("claim.exp", "tomorrow"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
 	}
 
 	for name, test := range testCases {
@@ -132,3 +284,1231 @@ func TestJWTHandlerServeToken(t *testing.T) {
 		t.Run(name, scenario)
 	}
 }
+
+func TestJWTHandlerServeTokenSignature(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveAlgorithm string
+		HaveKeys      func(*fake.JWTHandler) http.HandlerFunc
+	}{
+		"RS384": {
+			HaveAlgorithm: "RS384",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeCertificate },
+		},
+		"PS256": {
+			HaveAlgorithm: "PS256",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeCertificate },
+		},
+		"ES384": {
+			HaveAlgorithm: "ES384",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeCertificate },
+		},
+		"ES256K": {
+			HaveAlgorithm: "ES256K",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeCertificate },
+		},
+		"HS256": {
+			HaveAlgorithm: "HS256",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeSecret },
+		},
+		"HS512": {
+			HaveAlgorithm: "HS512",
+			HaveKeys:      func(h *fake.JWTHandler) http.HandlerFunc { return h.ServeSecret },
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			serve := func(handler http.HandlerFunc, endpoint string) string {
+				req := newRequest(t.Context(),
+					WithRequestPath("jwt"),
+					WithRequestPathValue("subject", "signature"),
+					WithRequestPath(endpoint),
+					WithRequestQuery("alg", test.HaveAlgorithm),
+					WithRequestQuery("length", "2048"),
+				)
+				w := httptest.NewRecorder()
+
+				handler(w, req)
+
+				return decodeSecret(t, w.Result())
+			}
+
+			token := serve(subject.ServeToken, "tokens")
+			keys := serve(test.HaveKeys(subject), "keys")
+
+			set, err := jwk.ParseString(keys)
+			if err != nil {
+				t.Fatalf("malformed JWK set: %v", err)
+			}
+
+			if _, err := jwt.ParseString(token, jwt.WithKeySet(set, jws.WithInferAlgorithmFromKey(true)), jwt.WithValidate(false)); err != nil {
+				t.Errorf("JWT signature verification failed: %v", err)
+			}
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestJWTHandlerServeTokenEncryption(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		HaveKeys    []requestOption
+		WantCode    int
+		WantNested  bool
+	}{
+		"RSA-OAEP": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "RSA-OAEP"),
+			},
+			WantCode: http.StatusOK,
+		},
+		"RSA-OAEP-256 nested": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "RSA-OAEP-256"),
+				WithRequestQuery("nested", "true"),
+			},
+			WantCode:   http.StatusOK,
+			WantNested: true,
+		},
+		"ECDH-ES nested": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "ECDH-ES"),
+				WithRequestQuery("recipient_curve", "P384"),
+				WithRequestQuery("nested", "true"),
+			},
+			HaveKeys: []requestOption{
+				WithRequestQuery("algorithm", "ECDSA"),
+				WithRequestQuery("curve", "P384"),
+			},
+			WantCode:   http.StatusOK,
+			WantNested: true,
+		},
+		"ECDH-ES+A256KW": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "ECDH-ES+A256KW"),
+				WithRequestQuery("content_encryption", "A128CBC-HS256"),
+			},
+			HaveKeys: []requestOption{
+				WithRequestQuery("algorithm", "ECDSA"),
+			},
+			WantCode: http.StatusOK,
+		},
+		"A256KW nested": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "A256KW"),
+				WithRequestQuery("nested", "true"),
+				WithRequestQuery("alg", "ES256"),
+			},
+			WantCode:   http.StatusOK,
+			WantNested: true,
+		},
+		"invalid encryption": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "RSA1_5"),
+			},
+			WantCode: http.StatusBadRequest,
+		},
+		"invalid content encryption": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "A256KW"),
+				WithRequestQuery("content_encryption", "A512GCM"),
+			},
+			WantCode: http.StatusBadRequest,
+		},
+		"invalid curve": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encryption", "ECDH-ES"),
+				WithRequestQuery("recipient_curve", "P224"),
+			},
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			serve := func(handler http.HandlerFunc, name, endpoint string, opts ...requestOption) *http.Response {
+				reqopt := []requestOption{
+					WithRequestPath("jwt"),
+					WithRequestPathValue("subject", name),
+					WithRequestPath(endpoint),
+					WithRequestQuery("length", "2048"),
+				}
+				reqopt = append(reqopt, opts...)
+				w := httptest.NewRecorder()
+
+				handler(w, newRequest(t.Context(), reqopt...))
+
+				return w.Result()
+			}
+
+			reqopt := append([]requestOption{WithRequestQuery("recipient", "partner")}, test.HaveRequest...)
+			res := serve(subject.ServeToken, "encryption", "tokens", reqopt...)
+			if res.StatusCode != test.WantCode {
+				t.Fatalf("invalid status code. got %d, want %d", res.StatusCode, test.WantCode)
+			}
+
+			if test.WantCode != http.StatusOK {
+				return
+			}
+
+			token := decodeSecret(t, res)
+			msg, err := jwe.Parse([]byte(token))
+			if err != nil {
+				t.Fatalf("malformed JWE: %v", err)
+			}
+
+			alg, _ := msg.ProtectedHeaders().Algorithm()
+			if _, ok := msg.ProtectedHeaders().KeyID(); !ok {
+				t.Error("JWE key ID: no such header")
+			}
+
+			var keys string
+			if alg == jwa.A256KW() {
+				keys = decodeSecret(t, serve(subject.ServeSecret, "partner", "secrets"))
+			} else {
+				keys = decodeSecret(t, serve(subject.ServePrivateKey, "partner", "keys", test.HaveKeys...))
+			}
+
+			set, err := jwk.ParseString(keys)
+			if err != nil {
+				t.Fatalf("malformed JWK set: %v", err)
+			}
+
+			key, _ := set.Key(0)
+			payload, err := jwe.Decrypt([]byte(token), jwe.WithKey(alg, key))
+			if err != nil {
+				t.Fatalf("JWE decryption failed: %v", err)
+			}
+
+			cty, _ := msg.ProtectedHeaders().ContentType()
+			if test.WantNested {
+				if cty != "JWT" {
+					t.Errorf("JWE content type: got %q, want JWT", cty)
+				}
+
+				assert.Assert(t, string(payload), assert.Assertions[string]{
+					assert.JWT(
+						assert.JWTSubject(assert.StringEqual("encryption")),
+					),
+				})
+			} else {
+				var claims DTO
+				if err := json.Unmarshal(payload, &claims); err != nil {
+					t.Fatalf("malformed JWE payload: %v", err)
+				}
+
+				assert.Assert(t, claims, assert.Assertions[DTO]{
+					assertDTOString("sub", assert.StringEqual("encryption")),
+				})
+			}
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestJWTHandlerServeSecret(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		WantCode    int
+		WantLength  int
+	}{
+		"default": {
+			WantCode:   http.StatusOK,
+			WantLength: 32,
+		},
+		"HS384": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "HS384"),
+			},
+			WantCode:   http.StatusOK,
+			WantLength: 48,
+		},
+		"HS512": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "HS512"),
+			},
+			WantCode:   http.StatusOK,
+			WantLength: 64,
+		},
+		"asymmetric": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("alg", "RS256"),
+			},
+			WantCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("jwt"),
+				WithRequestPathValue("subject", "secret"),
+				WithRequestPath("secrets"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeSecret(w, req)
+
+			res := w.Result()
+			if res.StatusCode != test.WantCode {
+				t.Fatalf("invalid status code. got %d, want %d", res.StatusCode, test.WantCode)
+			}
+
+			if test.WantCode != http.StatusOK {
+				return
+			}
+
+			set, err := jwk.ParseString(decodeSecret(t, res))
+			if err != nil {
+				t.Fatalf("malformed JWK set: %v", err)
+			}
+
+			key, _ := set.Key(0)
+			if key.KeyType().String() != "oct" {
+				t.Errorf("JWK key type: got %s, want oct", key.KeyType())
+			}
+
+			var secret []byte
+			if err := jwk.Export(key, &secret); err != nil {
+				t.Fatalf("unable to export secret: %v", err)
+			}
+
+			if len(secret) != test.WantLength {
+				t.Errorf("secret length: got %d, want %d", len(secret), test.WantLength)
+			}
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestJWTHandlerServeDiscovery(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("issuer",
+						assert.StringEqual("http://example.test/oauth2/spec"),
+					),
+					assertDTOString("jwks_uri",
+						assert.StringEqual("http://example.test/oauth2/spec/jwks"),
+					),
+					assertDTOString("token_endpoint",
+						assert.StringEqual("http://example.test/oauth2/spec/token"),
+					),
+					assertDTOString("authorization_endpoint",
+						assert.StringEqual("http://example.test/oauth2/spec/authorize"),
+					),
+					assertDTOString("userinfo_endpoint",
+						assert.StringEqual("http://example.test/oauth2/spec/userinfo"),
+					),
+				),
+			},
+		},
+		"query": {
+			HaveRequest: []requestOption{
+				WithRequestHeader("X-Forwarded-Proto", "https"),
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("issuer",
+						assert.StringEqual("https://example.test/oauth2/spec"),
+					),
+					assertDTOString("jwks_uri",
+						assert.StringEqual("https://example.test/oauth2/spec/jwks?algorithm=ecdsa"),
+					),
+				),
+			},
+		},
+		"invalid": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "dsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("oauth2"),
+				WithRequestPathValue("issuer", "spec"),
+				WithRequestPath(".well-known"),
+				WithRequestPath("openid-configuration"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeDiscovery(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestJWTHandlerServeJWKS(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+	subject := fake.NewJWTHandler(start, rnd, logger)
+	req := newRequest(t.Context(),
+		WithRequestPath("oauth2"),
+		WithRequestPathValue("issuer", "spec"),
+		WithRequestPath("jwks"),
+		WithRequestQuery("algorithm", "ecdsa"),
+	)
+	w := httptest.NewRecorder()
+
+	subject.ServeJWKS(w, req)
+
+	assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseHeader("Content-Type",
+			assert.StringContains("application/json"),
+		),
+		assert.HTTPResponseBody(func(t *testing.T, got []byte) {
+			set, err := jwk.Parse(got)
+			if err != nil {
+				t.Fatalf("malformed JWK set: %v", err)
+			}
+
+			if set.Len() != 1 {
+				t.Fatalf("JWK set size: got %d, want 1", set.Len())
+			}
+
+			key, _ := set.Key(0)
+			if _, ok := key.KeyID(); !ok {
+				t.Error("JWK key ID: no such field")
+			}
+
+			if key.KeyType().String() != "EC" {
+				t.Errorf("JWK key type: got %s, want EC", key.KeyType())
+			}
+		}),
+	})
+}
+
+func TestJWTHandlerServeOAuth2Token(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"client_credentials": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "client_credentials"),
+				WithRequestForm("client_id", "app"),
+				WithRequestForm("scope", "read write"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseHeader("Cache-Control",
+					assert.StringEqual("no-store"),
+				),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("token_type",
+						assert.StringEqual("Bearer"),
+					),
+					assertDTOString("scope",
+						assert.StringEqual("read write"),
+					),
+					assertDTOString("access_token",
+						assert.JWT(
+							assert.JWTIssuer(assert.StringEqual("http://example.test/oauth2/spec")),
+							assert.JWTSubject(assert.StringEqual("app")),
+						),
+					),
+					assertDTONoField("refresh_token"),
+				),
+			},
+		},
+		"client_credentials basic auth": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "client_credentials"),
+				WithRequestHeader("Authorization", "Basic YXBwOnNlY3JldA=="),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("access_token",
+						assert.JWT(
+							assert.JWTSubject(assert.StringEqual("app")),
+						),
+					),
+				),
+			},
+		},
+		"client_credentials without client": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "client_credentials"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusUnauthorized),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("error",
+						assert.StringEqual("invalid_client"),
+					),
+				),
+			},
+		},
+		"password": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "password"),
+				WithRequestForm("username", "jdoe"),
+				WithRequestForm("password", "hunter2"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("access_token",
+						assert.JWT(
+							assert.JWTSubject(assert.StringEqual("jdoe")),
+						),
+					),
+					assertDTOString("refresh_token",
+						assert.StringNotEmpty(),
+					),
+				),
+			},
+		},
+		"password without credentials": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "password"),
+				WithRequestForm("username", "jdoe"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("error",
+						assert.StringEqual("invalid_request"),
+					),
+				),
+			},
+		},
+		"unknown refresh token": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "refresh_token"),
+				WithRequestForm("refresh_token", "invalid"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("error",
+						assert.StringEqual("invalid_grant"),
+					),
+				),
+			},
+		},
+		"unsupported grant": {
+			HaveRequest: []requestOption{
+				WithRequestForm("grant_type", "implicit"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("error",
+						assert.StringEqual("unsupported_grant_type"),
+					),
+				),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("oauth2"),
+				WithRequestPathValue("issuer", "spec"),
+				WithRequestPath("token"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeOAuth2Token(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestJWTHandlerServeOAuth2TokenRefresh(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	seed := rand.NewSource(0)
+	rnd := rand.New(seed)
+	subject := fake.NewJWTHandler(start, rnd, logger)
+	token := func(form ...string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Token, "token", form...)
+	}
+	refreshToken := func(res *http.Response) string {
+		return decodeOAuth2Field(t, res, "refresh_token")
+	}
+
+	initial := refreshToken(token("grant_type", "password", "username", "jdoe", "password", "hunter2", "client_id", "app", "scope", "profile"))
+	if initial == "" {
+		t.Fatal("initial token response has no refresh token")
+	}
+
+	assert.Assert(t, token("grant_type", "refresh_token", "refresh_token", initial, "client_id", "other"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+	assert.Assert(t, token("grant_type", "refresh_token", "refresh_token", initial, "client_id", "app", "scope", "profile email"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_scope"),
+			),
+		),
+	})
+
+	rotated := token("grant_type", "refresh_token", "refresh_token", initial, "client_id", "app", "scope", "profile")
+	if rotated.StatusCode != http.StatusOK {
+		t.Fatalf("refresh status code: got %d, want %d", rotated.StatusCode, http.StatusOK)
+	}
+
+	if next := refreshToken(rotated); next == "" || next == initial {
+		t.Errorf("refresh token rotation: got %q, want new token", next)
+	}
+
+	assert.Assert(t, token("grant_type", "refresh_token", "refresh_token", initial), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_grant"),
+			),
+		),
+	})
+}
+
+func TestJWTHandlerServeOAuth2Introspection(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	seed := rand.NewSource(0)
+	rnd := rand.New(seed)
+	subject := fake.NewJWTHandler(start, rnd, logger)
+	foreign := fake.NewJWTHandler(start, rand.New(rand.NewSource(1)), logger)
+	introspect := func(form ...string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Introspection, "introspect", form...)
+	}
+	revoke := func(form ...string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Revocation, "revoke", form...)
+	}
+	inactive := assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBody(func(t *testing.T, got []byte) {
+			if want := `{"active":false}`; string(bytes.TrimSpace(got)) != want {
+				t.Errorf("introspection response: got %s, want %s", got, want)
+			}
+		}),
+	}
+
+	access := decodeOAuth2Field(t, serveOAuth2(t, subject.ServeOAuth2Token, "token",
+		"grant_type", "password", "username", "jdoe", "password", "hunter2", "client_id", "app", "scope", "profile"),
+		"access_token")
+	forged := decodeOAuth2Field(t, serveOAuth2(t, foreign.ServeOAuth2Token, "token",
+		"grant_type", "client_credentials", "client_id", "app"),
+		"access_token")
+	refresh := decodeOAuth2Field(t, serveOAuth2(t, subject.ServeOAuth2Token, "token",
+		"grant_type", "password", "username", "jdoe", "password", "hunter2"),
+		"refresh_token")
+
+	assert.Assert(t, introspect("token", access), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+			assertDTOString("sub",
+				assert.StringEqual("jdoe"),
+			),
+			assertDTOString("scope",
+				assert.StringEqual("profile"),
+			),
+			assertDTOString("client_id",
+				assert.StringEqual("app"),
+			),
+			assertDTOField("exp"),
+		),
+	})
+	assert.Assert(t, introspect("token", refresh), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+			assertDTOString("token_type",
+				assert.StringEqual("refresh_token"),
+			),
+		),
+	})
+	assert.Assert(t, introspect("token", refresh, "token_type_hint", "access_token"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+			assertDTOString("token_type",
+				assert.StringEqual("refresh_token"),
+			),
+		),
+	})
+	assert.Assert(t, introspect("token", access, "token_type_hint", "refresh_token"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+			assertDTOString("token_type",
+				assert.StringEqual("Bearer"),
+			),
+		),
+	})
+	assert.Assert(t, introspect("token", signExternalToken(t, subject, access)), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+			assertDTOString("sub",
+				assert.StringEqual("external"),
+			),
+		),
+	})
+	assert.Assert(t, introspect("token", forged), inactive)
+	assert.Assert(t, introspect("token", "malformed"), inactive)
+	assert.Assert(t, introspect(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+
+	assert.Assert(t, revoke("token", access), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+	})
+	assert.Assert(t, revoke("token", refresh, "token_type_hint", "refresh_token"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+	})
+	assert.Assert(t, revoke("token", "malformed"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+	})
+
+	assert.Assert(t, introspect("token", access), inactive)
+	assert.Assert(t, introspect("token", refresh), inactive)
+}
+
+func TestJWTHandlerServeOAuth2Authorize(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	seed := rand.NewSource(0)
+	rnd := rand.New(seed)
+	subject := fake.NewJWTHandler(start, rnd, logger)
+	verifier := "dBjftJeZ4CVP-mJ92K9OpqLW6ONlMxtPvw0ZfXlCaHsc"
+	challenge := "R3kHcrb2Vu9rdn3nTckJq43ft6DOFaYcdmkhJLDcFXY"
+	authorize := func(query ...string) *http.Response {
+		reqopt := []requestOption{
+			WithRequestPath("oauth2"),
+			WithRequestPathValue("issuer", "spec"),
+			WithRequestPath("authorize"),
+		}
+
+		for i := 0; i+1 < len(query); i += 2 {
+			reqopt = append(reqopt, WithRequestQuery(query[i], query[i+1]))
+		}
+
+		w := httptest.NewRecorder()
+		subject.ServeOAuth2Authorize(w, newRequest(t.Context(), reqopt...))
+
+		return w.Result()
+	}
+	redirect := func(res *http.Response) url.Values {
+		if res.StatusCode != http.StatusFound {
+			t.Fatalf("authorization status code: got %d, want %d", res.StatusCode, http.StatusFound)
+		}
+
+		location, err := url.Parse(res.Header.Get("Location"))
+		if err != nil {
+			t.Fatalf("malformed redirect location: %v", err)
+		}
+
+		return location.Query()
+	}
+	code := func(query ...string) string {
+		params := redirect(authorize(append(query, "response_type", "code", "client_id", "app", "redirect_uri", "https://app.test/callback")...))
+		if params.Get("code") == "" {
+			t.Fatalf("authorization redirect has no code: %v", params)
+		}
+
+		return params.Get("code")
+	}
+	token := func(form ...string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Token, "token", append(form, "grant_type", "authorization_code", "client_id", "app", "redirect_uri", "https://app.test/callback")...)
+	}
+
+	params := redirect(authorize("response_type", "code", "client_id", "app", "redirect_uri", "https://app.test/callback", "state", "xyz"))
+	if got := params.Get("state"); got != "xyz" {
+		t.Errorf("authorization state: got %q, want %q", got, "xyz")
+	}
+
+	params = redirect(authorize("response_type", "token", "client_id", "app", "redirect_uri", "https://app.test/callback"))
+	if got := params.Get("error"); got != "unsupported_response_type" {
+		t.Errorf("authorization error: got %q, want %q", got, "unsupported_response_type")
+	}
+
+	params = redirect(authorize("response_type", "code", "client_id", "app", "redirect_uri", "https://app.test/callback", "code_challenge", challenge))
+	if got := params.Get("error"); got != "invalid_request" {
+		t.Errorf("authorization error: got %q, want %q", got, "invalid_request")
+	}
+
+	assert.Assert(t, authorize("response_type", "code", "client_id", "app"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+	assert.Assert(t, authorize("response_type", "code", "client_id", "app", "redirect_uri", "https://app.test/callback", "prompt", "select_account"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseHeader("Content-Type",
+			assert.StringContains("text/html"),
+		),
+	})
+
+	res := token("code", code("code_challenge", challenge, "code_challenge_method", "S256", "nonce", "n-0S6", "login_hint", "jdoe", "scope", "openid"), "code_verifier", verifier)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("token status code: got %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	var dto DTO
+	if err := json.NewDecoder(res.Body).Decode(&dto); err != nil {
+		t.Fatalf("malformed token response: %v", err)
+	}
+
+	assert.Assert(t, dto, assert.Assertions[DTO]{
+		assertDTOField("access_token"),
+		assertDTOField("refresh_token"),
+		assertDTOString("id_token",
+			assert.JWT(
+				assert.JWTSubject(
+					assert.StringEqual("jdoe"),
+				),
+				assert.JWTAudience(
+					assert.StringEqual("app"),
+				),
+				assert.JWTClaim("nonce",
+					assert.StringEqual(`"n-0S6"`),
+				),
+			),
+		),
+	})
+
+	assert.Assert(t, token("code", code("code_challenge", challenge, "code_challenge_method", "S256"), "code_verifier", "wrong"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_grant"),
+			),
+		),
+	})
+	assert.Assert(t, token("code", code("code_challenge", challenge, "code_challenge_method", "S256")), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+
+	reused := code()
+	assert.Assert(t, token("code", reused), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+	})
+	assert.Assert(t, token("code", reused), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_grant"),
+			),
+		),
+	})
+
+	assert.Assert(t, token("code", code(), "code_verifier", verifier), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_grant"),
+			),
+		),
+	})
+	assert.Assert(t, serveOAuth2(t, subject.ServeOAuth2Token, "token", "grant_type", "authorization_code", "code", code(), "redirect_uri", "https://app.test/callback"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_grant"),
+			),
+		),
+	})
+
+	userinfo := func(bearer string) *http.Response {
+		req := newRequest(t.Context(),
+			WithRequestPath("oauth2"),
+			WithRequestPathValue("issuer", "spec"),
+			WithRequestPath("userinfo"),
+			WithRequestHeader("Authorization", "Bearer "+bearer),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeOAuth2UserInfo(w, req)
+
+		return w.Result()
+	}
+
+	access, _ := dto["access_token"].(string)
+	assert.Assert(t, userinfo(access), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("sub",
+				assert.StringEqual("jdoe"),
+			),
+			assertDTONoField("client_id"),
+		),
+	})
+	assert.Assert(t, userinfo("malformed"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusUnauthorized),
+		assert.HTTPResponseHeader("WWW-Authenticate",
+			assert.StringContains("invalid_token"),
+		),
+	})
+}
+
+func TestJWTHandlerServeIssuerRotation(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	seed := rand.NewSource(0)
+	rnd := rand.New(seed)
+	subject := fake.NewJWTHandler(start, rnd, logger)
+	keyIDs := func(data string) []string {
+		set, err := jwk.ParseString(data)
+		if err != nil {
+			t.Fatalf("malformed JWK set: %v", err)
+		}
+
+		result := make([]string, set.Len())
+		for i := range set.Len() {
+			key, _ := set.Key(i)
+			result[i], _ = key.KeyID()
+		}
+
+		return result
+	}
+	jwks := func() []string {
+		req := newRequest(t.Context(),
+			WithRequestPath("oauth2"),
+			WithRequestPathValue("issuer", "spec"),
+			WithRequestPath("jwks"),
+			WithRequestQuery("algorithm", "ecdsa"),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeJWKS(w, req)
+
+		return keyIDs(w.Body.String())
+	}
+	rotate := func(form ...string) string {
+		res := serveOAuth2(t, subject.ServeIssuerRotation, "rotate", append(form, "algorithm", "ecdsa")...)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("rotation status code: got %d, want %d", res.StatusCode, http.StatusOK)
+		}
+
+		kids := keyIDs(decodeSecret(t, res))
+		if len(kids) != 1 {
+			t.Fatalf("rotated JWK set size: got %d, want 1", len(kids))
+		}
+
+		return kids[0]
+	}
+	token := func(form ...string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Token, "token", append(form, "grant_type", "client_credentials", "client_id", "app", "algorithm", "ecdsa")...)
+	}
+	introspect := func(token string) *http.Response {
+		return serveOAuth2(t, subject.ServeOAuth2Introspection, "introspect", "token", token)
+	}
+	tokenKeyID := func(form ...string) string {
+		msg, err := jws.ParseString(decodeOAuth2Field(t, token(form...), "access_token"))
+		if err != nil {
+			t.Fatalf("malformed access token: %v", err)
+		}
+
+		kid, _ := msg.Signatures()[0].ProtectedHeaders().KeyID()
+
+		return kid
+	}
+
+	initial := jwks()
+	if len(initial) != 1 {
+		t.Fatalf("initial JWK set size: got %d, want 1", len(initial))
+	}
+
+	first := rotate()
+	if first == initial[0] {
+		t.Fatalf("rotated key ID: got %q, want new key ID", first)
+	}
+
+	if got := jwks(); !slices.Equal(got, []string{first, initial[0]}) {
+		t.Errorf("JWK set after rotation: got %v, want %v", got, []string{first, initial[0]})
+	}
+
+	if got := tokenKeyID(); got != first {
+		t.Errorf("token key ID: got %q, want %q", got, first)
+	}
+
+	if got := tokenKeyID("kid", initial[0]); got != initial[0] {
+		t.Errorf("token key ID: got %q, want %q", got, initial[0])
+	}
+
+	assert.Assert(t, token("kid", "unknown"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("error",
+				assert.StringEqual("invalid_request"),
+			),
+		),
+	})
+
+	retired := decodeOAuth2Field(t, token("kid", first), "access_token")
+	assert.Assert(t, introspect(retired), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", true),
+		),
+	})
+
+	assert.Assert(t, serveOAuth2(t, subject.ServeIssuerRotation, "rotate", "grace_period", "18446744073709551615"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+
+	second := rotate("grace_period", "0")
+	if got := jwks(); !slices.Equal(got, []string{second, initial[0]}) {
+		t.Errorf("JWK set after rotation without grace period: got %v, want %v", got, []string{second, initial[0]})
+	}
+
+	assert.Assert(t, token("kid", first), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+	assert.Assert(t, introspect(retired), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOBool("active", false),
+		),
+	})
+}
+
+func TestJWTHandlerServeVerify(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveForgery bool
+		HaveToken   string
+		HaveForm    []string
+		WantCode    int
+		WantValid   bool
+		WantFailure int
+	}{
+		"valid": {
+			HaveForm:  []string{"verify_at", "3600", "audience", "api"},
+			WantCode:  http.StatusOK,
+			WantValid: true,
+		},
+		"expired": {
+			HaveForm:    []string{"verify_at", "172800"},
+			WantCode:    http.StatusOK,
+			WantFailure: 1,
+		},
+		"not before": {
+			HaveForm:    []string{"verify_at", "-3600"},
+			WantCode:    http.StatusOK,
+			WantFailure: 1,
+		},
+		"audience": {
+			HaveForm:    []string{"verify_at", "3600", "audience", "other"},
+			WantCode:    http.StatusOK,
+			WantFailure: 1,
+		},
+		"foreign key": {
+			HaveForgery: true,
+			HaveForm:    []string{"verify_at", "172800", "audience", "other"},
+			WantCode:    http.StatusOK,
+			WantFailure: 3,
+		},
+		"malformed": {
+			HaveToken: "malformed",
+			WantCode:  http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("jwt-random-seed"))
+			subject := fake.NewJWTHandler(start, rnd, logger)
+			issuer := subject
+			if test.HaveForgery {
+				issuer = fake.NewJWTHandler(start, io.InfiniteReader([]byte("jwt-forged-seed")), logger)
+			}
+
+			req := newRequest(t.Context(),
+				WithRequestPath("jwt"),
+				WithRequestPathValue("subject", "verify"),
+				WithRequestPath("tokens"),
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("audience", "api"),
+			)
+			w := httptest.NewRecorder()
+
+			issuer.ServeToken(w, req)
+
+			token := decodeSecret(t, w.Result())
+			if test.HaveToken != "" {
+				token = test.HaveToken
+			}
+
+			reqopt := []requestOption{
+				WithRequestMethod(http.MethodPost),
+				WithRequestPath("jwt"),
+				WithRequestPath("verify"),
+				WithRequestForm("token", token),
+			}
+
+			for i := 0; i+1 < len(test.HaveForm); i += 2 {
+				reqopt = append(reqopt, WithRequestForm(test.HaveForm[i], test.HaveForm[i+1]))
+			}
+
+			w = httptest.NewRecorder()
+
+			subject.ServeVerify(w, newRequest(t.Context(), reqopt...))
+
+			if test.WantCode != http.StatusOK {
+				assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+					assert.HTTPResponseStatusCode(test.WantCode),
+				})
+
+				return
+			}
+
+			assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(test.WantCode),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", test.WantValid),
+					assertDTOLen("failures", test.WantFailure),
+					assertDTOField("header"),
+					assertDTOField("claims"),
+				),
+			})
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func signExternalToken(t *testing.T, subject *fake.JWTHandler, reference string) string {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	subject.ServePrivateKey(w, newRequest(t.Context(),
+		WithRequestPath("jwt"),
+		WithRequestPathValue("subject", "external"),
+		WithRequestPath("keys"),
+		WithRequestQuery("algorithm", "ecdsa"),
+	))
+
+	set, err := jwk.ParseString(decodeSecret(t, w.Result()))
+	if err != nil {
+		t.Fatalf("malformed JWK set: %v", err)
+	}
+
+	key, ok := set.Key(0)
+	if !ok {
+		t.Fatal("JWK set has no keys")
+	}
+
+	parsed, err := jwt.ParseInsecure([]byte(reference))
+	if err != nil {
+		t.Fatalf("malformed reference token: %v", err)
+	}
+
+	issuer, _ := parsed.Issuer()
+	token, err := jwt.NewBuilder().
+		Issuer(issuer).
+		Subject("external").
+		Expiration(time.Now().Add(time.Hour)).
+		Build()
+	if err != nil {
+		t.Fatalf("unable to build token: %v", err)
+	}
+
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.ES256(), key))
+	if err != nil {
+		t.Fatalf("unable to sign token: %v", err)
+	}
+
+	return string(signed)
+}
+
+func serveOAuth2(t *testing.T, handler http.HandlerFunc, endpoint string, form ...string) *http.Response {
+	t.Helper()
+
+	reqopt := []requestOption{
+		WithRequestMethod(http.MethodPost),
+		WithRequestPath("oauth2"),
+		WithRequestPathValue("issuer", "spec"),
+		WithRequestPath(endpoint),
+	}
+
+	for i := 0; i+1 < len(form); i += 2 {
+		reqopt = append(reqopt, WithRequestForm(form[i], form[i+1]))
+	}
+
+	w := httptest.NewRecorder()
+	handler(w, newRequest(t.Context(), reqopt...))
+
+	return w.Result()
+}
+
+func decodeOAuth2Field(t *testing.T, res *http.Response, field string) string {
+	t.Helper()
+
+	var dto DTO
+	if err := json.NewDecoder(res.Body).Decode(&dto); err != nil {
+		t.Fatalf("malformed OAuth2 response: %v", err)
+	}
+
+	result, _ := dto[field].(string)
+
+	return result
+}
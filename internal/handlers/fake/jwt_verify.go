@@ -0,0 +1,103 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/json"
+	nethttp "net/http"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jws"
+	"github.com/lestrrat-go/jwx/v3/jwt"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+type jwtVerification struct {
+	Valid    bool           `json:"valid"`
+	Header   map[string]any `json:"header"`
+	Claims   map[string]any `json:"claims"`
+	Failures []string       `json:"failures"`
+}
+
+func (h *JWTHandler) RouteVerify(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("jwt", "verify"), h.ServeVerify
+}
+
+func (h *JWTHandler) ServeVerify(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseJWTVerifyMeta(time.Now(), r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("verifying JWT", "meta", meta)
+
+	msg, err := jws.ParseString(meta.Token)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	token, err := jwt.ParseInsecure([]byte(meta.Token))
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	data := &jwtVerification{
+		Failures: []string{},
+	}
+
+	if err := decodeJWTSection(msg.Signatures()[0].ProtectedHeaders(), &data.Header); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if err := decodeJWTSection(token, &data.Claims); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	set, err := newJWTKeySet(h.verificationKeys(time.Now())...)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := jws.Verify([]byte(meta.Token), jws.WithKeySet(set, jws.WithInferAlgorithmFromKey(true))); err != nil {
+		data.Failures = append(data.Failures, err.Error())
+	}
+
+	clock := jwt.ClockFunc(meta.VerifyTime)
+	validators := []jwt.ValidateOption{
+		jwt.WithValidator(jwt.IsExpirationValid()),
+		jwt.WithValidator(jwt.IsNbfValid()),
+	}
+
+	if meta.Audience != "" {
+		validators = append(validators, jwt.WithAudience(meta.Audience))
+	}
+
+	for _, v := range validators {
+		if err := jwt.Validate(token, jwt.WithClock(clock), jwt.WithResetValidators(true), v); err != nil {
+			data.Failures = append(data.Failures, err.Error())
+		}
+	}
+
+	data.Valid = len(data.Failures) == 0
+
+	http.ServeJSON(w, data)
+}
+
+func decodeJWTSection(section any, v *map[string]any) error {
+	raw, err := json.Marshal(section)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
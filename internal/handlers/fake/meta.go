@@ -2,14 +2,22 @@ package fake
 
 import (
 	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"log/slog"
+	"math"
 	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/lestrrat-go/jwx/v3/jwa"
+	"github.com/lestrrat-go/jwx/v3/jwt"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 	"github.com/UiP9AV6Y/fake-secrets/internal/hash"
@@ -314,28 +322,38 @@ type CryptoMeta struct {
 }
 
 func ParseCryptoMeta(subject string, r *nethttp.Request) (*CryptoMeta, error) {
+	defaults := &CryptoMeta{
+		Length:     4096,
+		Algorithm:  crypto.AlgorithmRSA,
+		ECDSACurve: crypto.ECDSACurveP256,
+	}
+
+	return parseCryptoMeta(subject, "", defaults, r)
+}
+
+func parseCryptoMeta(subject, prefix string, defaults *CryptoMeta, r *nethttp.Request) (*CryptoMeta, error) {
 	err := r.ParseForm()
 	if err != nil {
 		return nil, err
 	}
 
-	length, err := http.ParseFormInt(r, "length", 4096)
+	length, err := http.ParseFormInt(r, prefix+"length", int64(defaults.Length))
 	if err != nil {
 		return nil, err
 	}
 
-	algo, err := http.ParseFormCryptoAlgorithm(r, "algorithm", crypto.AlgorithmRSA)
+	algo, err := http.ParseFormCryptoAlgorithm(r, prefix+"algorithm", defaults.Algorithm)
 	if err != nil {
 		return nil, err
 	}
 
-	curve, err := http.ParseFormECDSACurve(r, "curve", crypto.ECDSACurveP256)
+	curve, err := http.ParseFormECDSACurve(r, prefix+"curve", defaults.ECDSACurve)
 	if err != nil {
 		return nil, err
 	}
 
 	if length <= 0 {
-		return nil, fmt.Errorf("length must be a positive value, got %d", length)
+		return nil, fmt.Errorf("%slength must be a positive value, got %d", prefix, length)
 	}
 
 	result := &CryptoMeta{
@@ -348,6 +366,42 @@ func ParseCryptoMeta(subject string, r *nethttp.Request) (*CryptoMeta, error) {
 	return result, nil
 }
 
+func parseCertificateCryptoMeta(subject, prefix string, defaults *CryptoMeta, r *nethttp.Request) (*CryptoMeta, error) {
+	meta, err := parseCryptoMeta(subject, prefix, defaults, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireCertificateCurve(prefix, meta); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// neither x509 nor ssh are able to encode keys on secp256k1,
+// so the curve is reserved for JWS signatures
+func requireCertificateCurve(prefix string, meta *CryptoMeta) error {
+	if meta.Algorithm == crypto.AlgorithmECDSA && meta.ECDSACurve == crypto.ECDSACurveSecp256k1 {
+		return fmt.Errorf("unsupported %scurve for certificates: %s", prefix, meta.ECDSACurve)
+	}
+
+	return nil
+}
+
+func parseKeyFormat(r *nethttp.Request, fallback crypto.KeyFormat, algo crypto.Algorithm) (crypto.KeyFormat, error) {
+	format, err := http.ParseFormKeyFormat(r, "format", fallback)
+	if err != nil {
+		return format, err
+	}
+
+	if !format.Supports(algo) {
+		return format, fmt.Errorf("%s private key format does not support %s keys", format, algo)
+	}
+
+	return format, nil
+}
+
 func (m *CryptoMeta) LogValue() slog.Value {
 	return slog.GroupValue(m.LogAttrs()...)
 }
@@ -376,12 +430,22 @@ func (m *CryptoMeta) StructWriteTo(w io.Writer) (int, error) {
 	return 0, nil
 }
 
+// the authority routes share the path segment of the hostname,
+// so no leaf may be issued for the hostname they occupy
+const AuthorityHostname = "ca"
+
+var ErrReservedHostname = errors.New("hostname " + AuthorityHostname + " is reserved for the certificate authority")
+
 type SSHMeta struct {
 	StaticMeta `json:",inline"`
 	CryptoMeta `json:",inline"`
 }
 
 func ParseSSHMeta(hostname string, r *nethttp.Request) (*SSHMeta, error) {
+	if hostname == AuthorityHostname {
+		return nil, ErrReservedHostname
+	}
+
 	err := r.ParseForm()
 	if err != nil {
 		return nil, err
@@ -392,6 +456,10 @@ func ParseSSHMeta(hostname string, r *nethttp.Request) (*SSHMeta, error) {
 		return nil, err
 	}
 
+	if err := requireCertificateCurve("", crypt); err != nil {
+		return nil, err
+	}
+
 	static := NewStaticMeta(r)
 	result := &SSHMeta{
 		StaticMeta: *static,
@@ -422,131 +490,100 @@ func (m *SSHMeta) StructWriteTo(w io.Writer) (int, error) {
 	return 0, nil
 }
 
-type TLSMeta struct {
-	StaticMeta `json:",inline"`
-	CryptoMeta `json:",inline"`
-
-	Organization string `json:"organization,omitempty"`
-
-	ValidFor int64 `json:"valid_for,omitempty"`
-	ValidAt  int64 `json:"valid_at,omitempty"`
-}
-
-func ParseTLSMeta(hostname string, start time.Time, r *nethttp.Request) (*TLSMeta, error) {
-	err := r.ParseForm()
+func ParseSSHAuthorityMeta(r *nethttp.Request) (*SSHMeta, error) {
+	meta, err := ParseSSHMeta("", r)
 	if err != nil {
 		return nil, err
 	}
 
-	crypt, err := ParseCryptoMeta(hostname, r)
+	crypt, err := parseCertificateCryptoMeta("CA", "ca_", &meta.CryptoMeta, r)
 	if err != nil {
 		return nil, err
 	}
 
-	validAt, err := http.ParseFormInt(r, "valid_at", start.Unix())
+	meta.CryptoMeta = *crypt
+
+	return meta, nil
+}
+
+type SSHKeyMeta struct {
+	SSHMeta `json:",inline"`
+
+	Format     crypto.KeyFormat `json:"format"`
+	Passphrase string           `json:"passphrase,omitempty"`
+}
+
+func ParseSSHKeyMeta(hostname string, r *nethttp.Request) (*SSHKeyMeta, error) {
+	meta, err := ParseSSHMeta(hostname, r)
 	if err != nil {
 		return nil, err
 	}
 
-	validFor, err := http.ParseFormInt(r, "valid_for", 60*60*24*90) // 90 days
+	format, err := parseKeyFormat(r, crypto.KeyFormatOpenSSH, meta.Algorithm)
 	if err != nil {
 		return nil, err
 	}
 
-	if validFor <= 0 {
-		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
-	}
-
-	organization := http.ParseFormString(r, "organization", "Acme Co")
-	static := NewStaticMeta(r)
-	result := &TLSMeta{
-		StaticMeta:   *static,
-		CryptoMeta:   *crypt,
-		Organization: organization,
-		ValidFor:     validFor,
-		ValidAt:      validAt,
+	passphrase := http.ParseFormString(r, "passphrase", "")
+	result := &SSHKeyMeta{
+		SSHMeta:    *meta,
+		Format:     format,
+		Passphrase: passphrase,
 	}
 
 	return result, nil
 }
 
-func (m *TLSMeta) LogValue() slog.Value {
+func (m *SSHKeyMeta) LogValue() slog.Value {
 	return slog.GroupValue(m.LogAttrs()...)
 }
 
-func (m *TLSMeta) LogAttrs() []slog.Attr {
+func (m *SSHKeyMeta) LogAttrs() []slog.Attr {
 	attrs := []slog.Attr{
-		slog.String("organization", m.Organization),
-		slog.Int64("valid_for", m.ValidFor),
-		slog.Int64("valid_at", m.ValidAt),
+		slog.Any("format", m.Format),
+		slog.String("passphrase", m.Passphrase),
 	}
-	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
 
-	return append(attrs, m.CryptoMeta.LogAttrs()...)
+	return append(attrs, m.SSHMeta.LogAttrs()...)
 }
 
-func (m *TLSMeta) String() string {
-	return DescribeStruct(m, "TLSMeta")
+func (m *SSHKeyMeta) String() string {
+	return DescribeStruct(m, "SSHKeyMeta")
 }
 
-func (m *TLSMeta) StructWriteTo(w io.Writer) (int, error) {
-	_, _ = m.StaticMeta.StructWriteTo(w)
-	_, _ = m.CryptoMeta.StructWriteTo(w)
-	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
-	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
-	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
+func (m *SSHKeyMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.SSHMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", format=%s", m.Format)
+	_, _ = fmt.Fprintf(w, ", passphrase=%s", m.Passphrase)
 
 	return 0, nil
 }
 
-func (m *TLSMeta) NotBefore() time.Time {
-	return time.Unix(m.ValidAt, 0)
-}
-
-func (m *TLSMeta) NotAfter() time.Time {
-	return time.Unix(m.ValidAt+m.ValidFor, 0)
-}
-
-func (m *TLSMeta) Subject() pkix.Name {
-	result := pkix.Name{
-		Organization: []string{m.Organization},
-	}
-
-	return result
-}
-
-func (m *TLSMeta) SubjectAltNames() []string {
-	result := []string{
-		m.CryptoMeta.Subject,
-	}
-
-	return result
-}
-
-type JWTMeta struct {
-	StaticMeta `json:",inline"`
-	CryptoMeta `json:",inline"`
+type SSHCertificateMeta struct {
+	SSHMeta `json:",inline"`
 
-	Organization string `json:"organization,omitempty"`
-	Audience     string `json:"audience,omitempty"`
+	CertType        crypto.SSHCertType `json:"cert_type"`
+	Principals      []string           `json:"principals,omitempty"`
+	CriticalOptions map[string]string  `json:"critical_options,omitempty"`
+	Extensions      map[string]string  `json:"extensions,omitempty"`
+	AuthorityCrypto *CryptoMeta        `json:"authority_crypto,omitempty"`
 
 	ValidFor int64 `json:"valid_for,omitempty"`
 	ValidAt  int64 `json:"valid_at,omitempty"`
-	IssuedAt int64 `json:"issued_at,omitempty"`
 }
 
-func ParseJWTMeta(issuer string, start time.Time, r *nethttp.Request) (*JWTMeta, error) {
-	err := r.ParseForm()
+func ParseSSHCertificateMeta(hostname string, start time.Time, r *nethttp.Request) (*SSHCertificateMeta, error) {
+	meta, err := ParseSSHMeta(hostname, r)
 	if err != nil {
 		return nil, err
 	}
 
-	crypt, err := ParseCryptoMeta(issuer, r)
+	authorityCrypt, err := parseCertificateCryptoMeta("CA", "ca_", &meta.CryptoMeta, r)
 	if err != nil {
 		return nil, err
 	}
 
-	issuedAt, err := http.ParseFormInt(r, "issued_at", start.Unix())
+	certType, err := http.ParseFormSSHCertType(r, "cert_type", crypto.SSHCertTypeHost)
 	if err != nil {
 		return nil, err
 	}
@@ -556,241 +593,1749 @@ func ParseJWTMeta(issuer string, start time.Time, r *nethttp.Request) (*JWTMeta,
 		return nil, err
 	}
 
-	validFor, err := http.ParseFormInt(r, "valid_for", 60*60*2) // 2 hours
+	validFor, err := http.ParseFormInt(r, "valid_for", 60*60*24) // 1 day
 	if err != nil {
 		return nil, err
 	}
 
-	if crypt.ECDSACurve == crypto.ECDSACurveP224 {
-		return nil, fmt.Errorf("unsupported ECDSA curve: %s", crypt.ECDSACurve)
+	if validAt < 0 {
+		return nil, fmt.Errorf("valid_at must not be negative, got %d", validAt)
 	}
 
 	if validFor <= 0 {
 		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
 	}
 
-	organization := http.ParseHeaderBaseURL(r)
-	audience := http.ParseFormString(r, "audience", "")
-	static := NewStaticMeta(r)
-	result := &JWTMeta{
-		StaticMeta:   *static,
-		CryptoMeta:   *crypt,
-		Organization: organization,
-		Audience:     audience,
-		ValidFor:     validFor,
-		ValidAt:      validAt,
-		IssuedAt:     issuedAt,
+	principals := http.ParseFormStrings(r, "principal")
+	if len(principals) == 0 {
+		principals = []string{hostname}
+	}
+
+	extensions := http.ParseFormOptions(r, "extension")
+	if len(extensions) == 0 {
+		extensions = certType.Extensions()
+	}
+
+	criticalOptions := http.ParseFormOptions(r, "critical_option")
+	result := &SSHCertificateMeta{
+		SSHMeta:         *meta,
+		CertType:        certType,
+		Principals:      principals,
+		CriticalOptions: criticalOptions,
+		Extensions:      extensions,
+		AuthorityCrypto: authorityCrypt,
+		ValidFor:        validFor,
+		ValidAt:         validAt,
 	}
 
 	return result, nil
 }
 
-func (m *JWTMeta) LogValue() slog.Value {
+func (m *SSHCertificateMeta) LogValue() slog.Value {
 	return slog.GroupValue(m.LogAttrs()...)
 }
 
-func (m *JWTMeta) LogAttrs() []slog.Attr {
+func (m *SSHCertificateMeta) LogAttrs() []slog.Attr {
 	attrs := []slog.Attr{
-		slog.String("organization", m.Organization),
-		slog.String("audience", m.Audience),
+		slog.String("cert_type", m.CertType.String()),
+		slog.Any("principals", m.Principals),
+		slog.Any("critical_options", m.CriticalOptions),
+		slog.Any("extensions", m.Extensions),
 		slog.Int64("valid_for", m.ValidFor),
 		slog.Int64("valid_at", m.ValidAt),
-		slog.Int64("issued_at", m.IssuedAt),
 	}
-	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
 
-	return append(attrs, m.CryptoMeta.LogAttrs()...)
+	if m.AuthorityCrypto != nil {
+		attrs = append(attrs, slog.Any("authority_crypto", m.AuthorityCrypto))
+	}
+
+	return append(attrs, m.SSHMeta.LogAttrs()...)
 }
 
-func (m *JWTMeta) String() string {
-	return DescribeStruct(m, "JWTMeta")
+func (m *SSHCertificateMeta) String() string {
+	return DescribeStruct(m, "SSHCertificateMeta")
 }
 
-func (m *JWTMeta) StructWriteTo(w io.Writer) (int, error) {
-	_, _ = m.StaticMeta.StructWriteTo(w)
-	_, _ = m.CryptoMeta.StructWriteTo(w)
-	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
-	_, _ = fmt.Fprintf(w, ", audience=%s", m.Audience)
+func (m *SSHCertificateMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.SSHMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", cert_type=%s", m.CertType)
+	_, _ = fmt.Fprintf(w, ", principals=%v", m.Principals)
+	_, _ = fmt.Fprintf(w, ", critical_options=%v", m.CriticalOptions)
+	_, _ = fmt.Fprintf(w, ", extensions=%v", m.Extensions)
 	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
 	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
-	_, _ = fmt.Fprintf(w, ", issued_at=%d", m.IssuedAt)
 
 	return 0, nil
 }
 
-func (m *JWTMeta) IssuedAtClaim() time.Time {
-	return time.Unix(m.IssuedAt, 0)
-}
-
-func (m *JWTMeta) NotBeforeClaim() time.Time {
-	return time.Unix(m.ValidAt, 0)
+func (m *SSHCertificateMeta) ValidAfter() uint64 {
+	return uint64(m.ValidAt)
 }
 
-func (m *JWTMeta) ExpirationClaim() time.Time {
-	return time.Unix(m.ValidAt+m.ValidFor, 0)
+func (m *SSHCertificateMeta) ValidBefore() uint64 {
+	return uint64(m.ValidAt + m.ValidFor)
 }
 
-func (m *JWTMeta) SubjectClaim() string {
-	return m.Subject
-}
+type SSHKnownHostsMeta struct {
+	SSHMeta `json:",inline"`
 
-func (m *JWTMeta) IssuerClaim() string {
-	return m.Organization
+	Port            int64       `json:"port"`
+	Addresses       []string    `json:"addresses,omitempty"`
+	Hashed          bool        `json:"hashed"`
+	CertAuthority   bool        `json:"cert_authority"`
+	AuthorityCrypto *CryptoMeta `json:"authority_crypto,omitempty"`
 }
 
-func (m *JWTMeta) SignatureAlgorithm() jwa.SignatureAlgorithm {
-	if m.Algorithm == crypto.AlgorithmECDSA {
-		return m.ECDSACurve.SignatureAlgorithm()
+func ParseSSHKnownHostsMeta(hostname string, r *nethttp.Request) (*SSHKnownHostsMeta, error) {
+	meta, err := ParseSSHMeta(hostname, r)
+	if err != nil {
+		return nil, err
 	}
 
-	return m.Algorithm.SignatureAlgorithm()
-}
-
-type OTPMeta struct {
-	StaticMeta `json:",inline"`
-
-	Organization string `json:"organization,omitempty"`
-	Subject      string `json:"subject,omitempty"`
-
-	Length int `json:"length,omitempty"`
-
-	Algorithm hash.Algorithm `json:"algorithm,omitempty"`
-}
-
-func ParseOTPMeta(subject string, r *nethttp.Request) (*OTPMeta, error) {
-	err := r.ParseForm()
+	port, err := http.ParseFormInt(r, "port", 22)
 	if err != nil {
 		return nil, err
 	}
 
-	length, err := http.ParseFormInt(r, "length", 20)
+	if port < 1 || port > 65535 {
+		return nil, fmt.Errorf("port must be a valid TCP port, got %d", port)
+	}
+
+	hashed, err := http.ParseFormBool(r, "hashed", false)
 	if err != nil {
 		return nil, err
 	}
 
-	algo, err := http.ParseFormHashAlgorithm(r, "algorithm", hash.AlgorithmSHA1)
+	certAuthority, err := http.ParseFormBool(r, "cert_authority", false)
 	if err != nil {
 		return nil, err
 	}
 
-	if length <= 0 {
-		return nil, fmt.Errorf("length must be a positive value, got %d", length)
+	var authorityCrypt *CryptoMeta
+	if certAuthority {
+		authorityCrypt, err = parseCertificateCryptoMeta("CA", "ca_", &meta.CryptoMeta, r)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	organization := http.ParseFormString(r, "organization", "Vault")
-	static := NewStaticMeta(r)
-	result := &OTPMeta{
-		StaticMeta:   *static,
-		Organization: organization,
-		Subject:      subject,
-		Length:       int(length),
-		Algorithm:    algo,
+	addresses := append([]string{hostname}, http.ParseFormStrings(r, "address")...)
+	result := &SSHKnownHostsMeta{
+		SSHMeta:         *meta,
+		Port:            port,
+		Addresses:       addresses,
+		Hashed:          hashed,
+		CertAuthority:   certAuthority,
+		AuthorityCrypto: authorityCrypt,
 	}
 
 	return result, nil
 }
 
-func (m *OTPMeta) LogValue() slog.Value {
+func (m *SSHKnownHostsMeta) LogValue() slog.Value {
 	return slog.GroupValue(m.LogAttrs()...)
 }
 
-func (m *OTPMeta) LogAttrs() []slog.Attr {
+func (m *SSHKnownHostsMeta) LogAttrs() []slog.Attr {
 	attrs := []slog.Attr{
-		slog.String("organization", m.Organization),
-		slog.String("subject", m.Subject),
-		slog.Int("length", m.Length),
+		slog.Int64("port", m.Port),
+		slog.Any("addresses", m.Addresses),
+		slog.Bool("hashed", m.Hashed),
+		slog.Bool("cert_authority", m.CertAuthority),
 	}
 
-	return append(attrs, m.StaticMeta.LogAttrs()...)
+	if m.AuthorityCrypto != nil {
+		attrs = append(attrs, slog.Any("authority_crypto", m.AuthorityCrypto))
+	}
+
+	return append(attrs, m.SSHMeta.LogAttrs()...)
 }
 
-func (m *OTPMeta) String() string {
-	return DescribeStruct(m, "OTPMeta")
+func (m *SSHKnownHostsMeta) String() string {
+	return DescribeStruct(m, "SSHKnownHostsMeta")
 }
 
-func (m *OTPMeta) StructWriteTo(w io.Writer) (int, error) {
-	_, _ = m.StaticMeta.StructWriteTo(w)
-	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
-	_, _ = fmt.Fprintf(w, ", subject=%s", m.Subject)
-	_, _ = fmt.Fprintf(w, ", length=%d", m.Length)
+func (m *SSHKnownHostsMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.SSHMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", port=%d", m.Port)
+	_, _ = fmt.Fprintf(w, ", addresses=%v", m.Addresses)
+	_, _ = fmt.Fprintf(w, ", hashed=%t", m.Hashed)
+	_, _ = fmt.Fprintf(w, ", cert_authority=%t", m.CertAuthority)
+
+	if m.AuthorityCrypto != nil {
+		_, _ = fmt.Fprintf(w, ", authority_crypto=%s", m.AuthorityCrypto)
+	}
 
 	return 0, nil
 }
 
-type TOTPMeta struct {
-	StaticMeta `json:",inline"`
-	OTPMeta    `json:",inline"`
+type SSHAuthorizedKeysMeta struct {
+	SSHMeta `json:",inline"`
 
-	ValidAt  int64 `json:"valid_at,omitempty"`
-	ValidFor int64 `json:"valid_for,omitempty"`
+	Command         string      `json:"command,omitempty"`
+	From            []string    `json:"from,omitempty"`
+	Environment     []string    `json:"environment,omitempty"`
+	Options         []string    `json:"options,omitempty"`
+	Principals      []string    `json:"principals,omitempty"`
+	CertAuthority   bool        `json:"cert_authority"`
+	AuthorityCrypto *CryptoMeta `json:"authority_crypto,omitempty"`
 }
 
-func ParseTOTPMeta(subject string, r *nethttp.Request) (*TOTPMeta, error) {
-	err := r.ParseForm()
-	if err != nil {
-		return nil, err
-	}
-
-	shared, err := ParseOTPMeta(subject, r)
+func ParseSSHAuthorizedKeysMeta(hostname string, r *nethttp.Request) (*SSHAuthorizedKeysMeta, error) {
+	meta, err := ParseSSHMeta(hostname, r)
 	if err != nil {
 		return nil, err
 	}
 
-	validAt, err := http.ParseFormInt(r, "valid_at", time.Now().Unix())
+	certAuthority, err := http.ParseFormBool(r, "cert_authority", false)
 	if err != nil {
 		return nil, err
 	}
 
-	validFor, err := http.ParseFormInt(r, "valid_for", 30) // 30 seconds
-	if err != nil {
-		return nil, err
+	var authorityCrypt *CryptoMeta
+	if certAuthority {
+		authorityCrypt, err = parseCertificateCryptoMeta("CA", "ca_", &meta.CryptoMeta, r)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	if validFor <= 0 {
-		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
+	environment := http.ParseFormStrings(r, "environment")
+	for _, e := range environment {
+		if !strings.Contains(e, "=") {
+			return nil, fmt.Errorf("environment must be in NAME=value format, got %q", e)
+		}
 	}
 
-	static := NewStaticMeta(r)
-	result := &TOTPMeta{
-		StaticMeta: *static,
-		OTPMeta:    *shared,
-		ValidAt:    validAt,
-		ValidFor:   validFor,
+	command := http.ParseFormString(r, "command", "")
+	result := &SSHAuthorizedKeysMeta{
+		SSHMeta:         *meta,
+		Command:         command,
+		From:            http.ParseFormStrings(r, "from"),
+		Environment:     environment,
+		Options:         http.ParseFormStrings(r, "option"),
+		Principals:      http.ParseFormStrings(r, "principal"),
+		CertAuthority:   certAuthority,
+		AuthorityCrypto: authorityCrypt,
 	}
 
 	return result, nil
 }
 
-func (m *TOTPMeta) LogValue() slog.Value {
+func (m *SSHAuthorizedKeysMeta) LogValue() slog.Value {
 	return slog.GroupValue(m.LogAttrs()...)
 }
 
-func (m *TOTPMeta) LogAttrs() []slog.Attr {
+func (m *SSHAuthorizedKeysMeta) LogAttrs() []slog.Attr {
 	attrs := []slog.Attr{
-		slog.Int64("valid_at", m.ValidAt),
-		slog.Int64("valid_for", m.ValidFor),
+		slog.String("command", m.Command),
+		slog.Any("from", m.From),
+		slog.Any("environment", m.Environment),
+		slog.Any("options", m.Options),
+		slog.Any("principals", m.Principals),
+		slog.Bool("cert_authority", m.CertAuthority),
 	}
-	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
 
-	return append(attrs, m.OTPMeta.LogAttrs()...)
+	if m.AuthorityCrypto != nil {
+		attrs = append(attrs, slog.Any("authority_crypto", m.AuthorityCrypto))
+	}
+
+	return append(attrs, m.SSHMeta.LogAttrs()...)
 }
 
-func (m *TOTPMeta) String() string {
-	return DescribeStruct(m, "TOTPMeta")
+func (m *SSHAuthorizedKeysMeta) String() string {
+	return DescribeStruct(m, "SSHAuthorizedKeysMeta")
 }
 
-func (m *TOTPMeta) StructWriteTo(w io.Writer) (int, error) {
-	_, _ = m.StaticMeta.StructWriteTo(w)
-	_, _ = m.OTPMeta.StructWriteTo(w)
-	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
-	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
+func (m *SSHAuthorizedKeysMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.SSHMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", command=%s", m.Command)
+	_, _ = fmt.Fprintf(w, ", from=%v", m.From)
+	_, _ = fmt.Fprintf(w, ", environment=%v", m.Environment)
+	_, _ = fmt.Fprintf(w, ", options=%v", m.Options)
+	_, _ = fmt.Fprintf(w, ", principals=%v", m.Principals)
+	_, _ = fmt.Fprintf(w, ", cert_authority=%t", m.CertAuthority)
+
+	if m.AuthorityCrypto != nil {
+		_, _ = fmt.Fprintf(w, ", authority_crypto=%s", m.AuthorityCrypto)
+	}
 
 	return 0, nil
 }
 
-type HOTPMeta struct {
+type TLSMeta struct {
 	StaticMeta `json:",inline"`
-	OTPMeta    `json:",inline"`
+	CryptoMeta `json:",inline"`
+
+	Organization string   `json:"organization,omitempty"`
+	CommonName   string   `json:"common_name,omitempty"`
+	AltNames     []string `json:"alt_names,omitempty"`
+
+	Usage           crypto.Usage     `json:"usage,omitempty"`
+	Authority       crypto.Authority `json:"authority,omitempty"`
+	AuthorityCrypto *CryptoMeta      `json:"authority_crypto,omitempty"`
+
+	ValidFor int64 `json:"valid_for,omitempty"`
+	ValidAt  int64 `json:"valid_at,omitempty"`
+}
+
+func ParseTLSMeta(hostname string, start time.Time, r *nethttp.Request) (*TLSMeta, error) {
+	if hostname == AuthorityHostname {
+		return nil, ErrReservedHostname
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	crypt, err := ParseCryptoMeta(hostname, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireCertificateCurve("", crypt); err != nil {
+		return nil, err
+	}
+
+	validAt, err := http.ParseFormInt(r, "valid_at", start.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	validFor, err := http.ParseFormInt(r, "valid_for", 60*60*24*90) // 90 days
+	if err != nil {
+		return nil, err
+	}
+
+	if validFor <= 0 {
+		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
+	}
+
+	usage, err := http.ParseFormUsage(r, "usage", crypto.UsageServer)
+	if err != nil {
+		return nil, err
+	}
+
+	authority, err := http.ParseFormAuthority(r, "ca", crypto.AuthorityNone)
+	if err != nil {
+		return nil, err
+	}
+
+	var authorityCrypt *CryptoMeta
+	if authority != crypto.AuthorityNone {
+		authorityCrypt, err = parseCertificateCryptoMeta(authority.String(), "ca_", crypt, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commonName := ""
+	if usage == crypto.UsageClient {
+		commonName = hostname
+	}
+
+	commonName = http.ParseFormString(r, "common_name", commonName)
+	altNames := http.ParseFormStrings(r, "alt_name")
+	organization := http.ParseFormString(r, "organization", "Acme Co")
+	static := NewStaticMeta(r)
+	result := &TLSMeta{
+		StaticMeta:      *static,
+		CryptoMeta:      *crypt,
+		Organization:    organization,
+		CommonName:      commonName,
+		AltNames:        altNames,
+		Usage:           usage,
+		Authority:       authority,
+		AuthorityCrypto: authorityCrypt,
+		ValidFor:        validFor,
+		ValidAt:         validAt,
+	}
+
+	return result, nil
+}
+
+func ParseTLSAuthorityMeta(start time.Time, r *nethttp.Request) (*TLSMeta, error) {
+	meta, err := ParseTLSMeta("", start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := meta.requireAuthority(r); err != nil {
+		return nil, err
+	}
+
+	meta.CryptoMeta = *meta.AuthorityCrypto
+
+	return meta, nil
+}
+
+func (m *TLSMeta) requireAuthority(r *nethttp.Request) (err error) {
+	if m.Authority != crypto.AuthorityNone {
+		return nil
+	}
+
+	m.Authority = crypto.AuthorityRoot
+	m.AuthorityCrypto, err = parseCertificateCryptoMeta(m.Authority.String(), "ca_", &m.CryptoMeta, r)
+
+	return
+}
+
+func (m *TLSMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("organization", m.Organization),
+		slog.String("common_name", m.CommonName),
+		slog.Any("alt_names", m.AltNames),
+		slog.Any("usage", m.Usage),
+		slog.Any("authority", m.Authority),
+		slog.Int64("valid_for", m.ValidFor),
+		slog.Int64("valid_at", m.ValidAt),
+	}
+
+	if m.AuthorityCrypto != nil {
+		attrs = append(attrs, slog.Any("authority_crypto", m.AuthorityCrypto))
+	}
+
+	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
+
+	return append(attrs, m.CryptoMeta.LogAttrs()...)
+}
+
+func (m *TLSMeta) String() string {
+	return DescribeStruct(m, "TLSMeta")
+}
+
+func (m *TLSMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = m.CryptoMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
+	_, _ = fmt.Fprintf(w, ", common_name=%s", m.CommonName)
+	_, _ = fmt.Fprintf(w, ", alt_names=%v", m.AltNames)
+	_, _ = fmt.Fprintf(w, ", usage=%s", m.Usage)
+	_, _ = fmt.Fprintf(w, ", authority=%s", m.Authority)
+
+	if m.AuthorityCrypto != nil {
+		_, _ = fmt.Fprintf(w, ", authority_crypto=%s", m.AuthorityCrypto)
+	}
+
+	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
+	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
+
+	return 0, nil
+}
+
+func (m *TLSMeta) AuthorityQuery() url.Values {
+	result := url.Values{}
+
+	if m.AuthorityCrypto == nil {
+		return result
+	}
+
+	result.Set("ca", m.Authority.String())
+	result.Set("ca_algorithm", m.AuthorityCrypto.Algorithm.String())
+	result.Set("ca_length", strconv.Itoa(m.AuthorityCrypto.Length))
+	result.Set("ca_curve", m.AuthorityCrypto.ECDSACurve.String())
+	result.Set("organization", m.Organization)
+
+	return result
+}
+
+func (m *TLSMeta) NotBefore() time.Time {
+	return time.Unix(m.ValidAt, 0)
+}
+
+func (m *TLSMeta) NotAfter() time.Time {
+	return time.Unix(m.ValidAt+m.ValidFor, 0)
+}
+
+func (m *TLSMeta) Subject() pkix.Name {
+	result := pkix.Name{
+		Organization: []string{m.Organization},
+		CommonName:   m.CommonName,
+	}
+
+	return result
+}
+
+func (m *TLSMeta) SubjectAltNames() []string {
+	result := []string{
+		m.CryptoMeta.Subject,
+	}
+
+	return append(result, m.AltNames...)
+}
+
+type TLSKeyMeta struct {
+	TLSMeta `json:",inline"`
+
+	Format     crypto.KeyFormat `json:"format"`
+	Passphrase string           `json:"passphrase,omitempty"`
+}
+
+func ParseTLSKeyMeta(hostname string, start time.Time, r *nethttp.Request) (*TLSKeyMeta, error) {
+	meta, err := ParseTLSMeta(hostname, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTLSKeyMeta(meta, r)
+}
+
+func ParseTLSAuthorityKeyMeta(start time.Time, r *nethttp.Request) (*TLSKeyMeta, error) {
+	meta, err := ParseTLSAuthorityMeta(start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTLSKeyMeta(meta, r)
+}
+
+func newTLSKeyMeta(meta *TLSMeta, r *nethttp.Request) (*TLSKeyMeta, error) {
+	format, err := parseKeyFormat(r, crypto.KeyFormatPKCS8, meta.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase := http.ParseFormString(r, "passphrase", "")
+	result := &TLSKeyMeta{
+		TLSMeta:    *meta,
+		Format:     format,
+		Passphrase: passphrase,
+	}
+
+	return result, nil
+}
+
+func (m *TLSKeyMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSKeyMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Any("format", m.Format),
+		slog.String("passphrase", m.Passphrase),
+	}
+
+	return append(attrs, m.TLSMeta.LogAttrs()...)
+}
+
+func (m *TLSKeyMeta) String() string {
+	return DescribeStruct(m, "TLSKeyMeta")
+}
+
+func (m *TLSKeyMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TLSMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", format=%s", m.Format)
+	_, _ = fmt.Fprintf(w, ", passphrase=%s", m.Passphrase)
+
+	return 0, nil
+}
+
+type TLSSigningMeta struct {
+	TLSMeta `json:",inline"`
+
+	Chain bool `json:"chain"`
+}
+
+func ParseTLSSigningMeta(subject string, start time.Time, r *nethttp.Request) (*TLSSigningMeta, error) {
+	meta, err := ParseTLSMeta(subject, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := meta.requireAuthority(r); err != nil {
+		return nil, err
+	}
+
+	chain, err := http.ParseFormBool(r, "chain", false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TLSSigningMeta{
+		TLSMeta: *meta,
+		Chain:   chain,
+	}
+
+	return result, nil
+}
+
+func (m *TLSSigningMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSSigningMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Bool("chain", m.Chain),
+	}
+
+	return append(attrs, m.TLSMeta.LogAttrs()...)
+}
+
+func (m *TLSSigningMeta) String() string {
+	return DescribeStruct(m, "TLSSigningMeta")
+}
+
+func (m *TLSSigningMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TLSMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", chain=%t", m.Chain)
+
+	return 0, nil
+}
+
+type TLSRevocationMeta struct {
+	TLSMeta `json:",inline"`
+
+	Reason    int   `json:"reason"`
+	RevokedAt int64 `json:"revoked_at,omitempty"`
+}
+
+func ParseTLSRevocationMeta(subject string, start time.Time, r *nethttp.Request) (*TLSRevocationMeta, error) {
+	meta, err := ParseTLSMeta(subject, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.Authority == crypto.AuthorityNone {
+		return nil, ErrNoAuthority
+	}
+
+	reason, err := http.ParseFormInt(r, "reason", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	revokedAt, err := http.ParseFormInt(r, "revoked_at", time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	// RFC 5280, section 5.3.1: value 7 is not used
+	if reason < 0 || reason > 10 || reason == 7 {
+		return nil, fmt.Errorf("invalid revocation reason code %d", reason)
+	}
+
+	result := &TLSRevocationMeta{
+		TLSMeta:   *meta,
+		Reason:    int(reason),
+		RevokedAt: revokedAt,
+	}
+
+	return result, nil
+}
+
+func (m *TLSRevocationMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSRevocationMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int("reason", m.Reason),
+		slog.Int64("revoked_at", m.RevokedAt),
+	}
+
+	return append(attrs, m.TLSMeta.LogAttrs()...)
+}
+
+func (m *TLSRevocationMeta) String() string {
+	return DescribeStruct(m, "TLSRevocationMeta")
+}
+
+func (m *TLSRevocationMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TLSMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", reason=%d", m.Reason)
+	_, _ = fmt.Fprintf(w, ", revoked_at=%d", m.RevokedAt)
+
+	return 0, nil
+}
+
+func (m *TLSRevocationMeta) RevocationTime() time.Time {
+	return time.Unix(m.RevokedAt, 0)
+}
+
+type TLSStoreMeta struct {
+	TLSMeta `json:",inline"`
+
+	Password string `json:"password"`
+}
+
+func ParseTLSStoreMeta(subject string, start time.Time, r *nethttp.Request) (*TLSStoreMeta, error) {
+	meta, err := ParseTLSMeta(subject, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	password := http.ParseFormString(r, "password", "changeit")
+	result := &TLSStoreMeta{
+		TLSMeta:  *meta,
+		Password: password,
+	}
+
+	return result, nil
+}
+
+func ParseTLSTrustStoreMeta(start time.Time, r *nethttp.Request) (*TLSStoreMeta, error) {
+	meta, err := ParseTLSAuthorityMeta(start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	password := http.ParseFormString(r, "password", "changeit")
+	result := &TLSStoreMeta{
+		TLSMeta:  *meta,
+		Password: password,
+	}
+
+	return result, nil
+}
+
+func (m *TLSStoreMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSStoreMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("password", m.Password),
+	}
+
+	return append(attrs, m.TLSMeta.LogAttrs()...)
+}
+
+func (m *TLSStoreMeta) String() string {
+	return DescribeStruct(m, "TLSStoreMeta")
+}
+
+func (m *TLSStoreMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TLSMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", password=%s", m.Password)
+
+	return 0, nil
+}
+
+type TLSKeyStoreMeta struct {
+	TLSStoreMeta `json:",inline"`
+
+	KeyPassword string `json:"key_password"`
+}
+
+func ParseTLSKeyStoreMeta(subject string, start time.Time, r *nethttp.Request) (*TLSKeyStoreMeta, error) {
+	meta, err := ParseTLSStoreMeta(subject, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPassword := http.ParseFormString(r, "key_password", meta.Password)
+	result := &TLSKeyStoreMeta{
+		TLSStoreMeta: *meta,
+		KeyPassword:  keyPassword,
+	}
+
+	return result, nil
+}
+
+func (m *TLSKeyStoreMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TLSKeyStoreMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("key_password", m.KeyPassword),
+	}
+
+	return append(attrs, m.TLSStoreMeta.LogAttrs()...)
+}
+
+func (m *TLSKeyStoreMeta) String() string {
+	return DescribeStruct(m, "TLSKeyStoreMeta")
+}
+
+func (m *TLSKeyStoreMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TLSStoreMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", key_password=%s", m.KeyPassword)
+
+	return 0, nil
+}
+
+type JWTMeta struct {
+	StaticMeta `json:",inline"`
+	CryptoMeta `json:",inline"`
+
+	Organization string              `json:"organization,omitempty"`
+	Audience     string              `json:"audience,omitempty"`
+	JWSAlgorithm crypto.JWSAlgorithm `json:"alg,omitempty"`
+	KeyID        string              `json:"kid,omitempty"`
+
+	ValidFor int64 `json:"valid_for,omitempty"`
+	ValidAt  int64 `json:"valid_at,omitempty"`
+	IssuedAt int64 `json:"issued_at,omitempty"`
+
+	Claims map[string]any `json:"claims,omitempty"`
+}
+
+func ParseJWTMeta(issuer string, start time.Time, r *nethttp.Request) (*JWTMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	crypt, err := ParseCryptoMeta(issuer, r)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedAt, err := http.ParseFormInt(r, "issued_at", start.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	validAt, err := http.ParseFormInt(r, "valid_at", start.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	validFor, err := http.ParseFormInt(r, "valid_for", 60*60*2) // 2 hours
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := http.ParseFormJWSAlgorithm(r, "alg", crypto.JWSAlgorithmNone)
+	if err != nil {
+		return nil, err
+	}
+
+	if algo := alg.KeyAlgorithm(); algo != 0 {
+		if r.FormValue("algorithm") != "" && crypt.Algorithm != algo {
+			return nil, fmt.Errorf("alg %s requires %s keys, got %s", alg, algo, crypt.Algorithm)
+		}
+
+		crypt.Algorithm = algo
+	}
+
+	if curve := alg.ECDSACurve(); curve != 0 {
+		if r.FormValue("curve") != "" && crypt.ECDSACurve != curve {
+			return nil, fmt.Errorf("alg %s requires ECDSA curve %s, got %s", alg, curve, crypt.ECDSACurve)
+		}
+
+		crypt.ECDSACurve = curve
+	}
+
+	if crypt.ECDSACurve == crypto.ECDSACurveP224 {
+		return nil, fmt.Errorf("unsupported ECDSA curve: %s", crypt.ECDSACurve)
+	}
+
+	if validFor <= 0 {
+		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
+	}
+
+	claims, err := parseJWTClaims(r)
+	if err != nil {
+		return nil, err
+	}
+
+	organization := http.ParseHeaderBaseURL(r)
+	audience := http.ParseFormString(r, "audience", "")
+	static := NewStaticMeta(r)
+	result := &JWTMeta{
+		StaticMeta:   *static,
+		CryptoMeta:   *crypt,
+		Organization: organization,
+		Audience:     audience,
+		JWSAlgorithm: alg,
+		KeyID:        http.ParseFormString(r, "kid", ""),
+		ValidFor:     validFor,
+		ValidAt:      validAt,
+		IssuedAt:     issuedAt,
+		Claims:       claims,
+	}
+
+	return result, nil
+}
+
+func parseJWTClaims(r *nethttp.Request) (map[string]any, error) {
+	result := map[string]any{}
+
+	if http.IsJSONRequest(r) {
+		dec := json.NewDecoder(io.LimitReader(r.Body, MaxJWTClaimsSize))
+		dec.UseNumber()
+
+		if err := dec.Decode(&result); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("malformed claims document: %w", err)
+		}
+	}
+
+	for name, values := range http.ParseFormPrefixed(r, "claim.") {
+		if len(values) == 1 {
+			result[name] = values[0]
+		} else {
+			result[name] = values
+		}
+	}
+
+	token := jwt.New()
+	for name, value := range result {
+		if err := token.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid claim %q: %w", name, err)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	return result, nil
+}
+
+func (m *JWTMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *JWTMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("organization", m.Organization),
+		slog.String("audience", m.Audience),
+		slog.Any("alg", m.JWSAlgorithm),
+		slog.String("kid", m.KeyID),
+		slog.Int64("valid_for", m.ValidFor),
+		slog.Int64("valid_at", m.ValidAt),
+		slog.Int64("issued_at", m.IssuedAt),
+		slog.Any("claims", m.Claims),
+	}
+	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
+
+	return append(attrs, m.CryptoMeta.LogAttrs()...)
+}
+
+func (m *JWTMeta) String() string {
+	return DescribeStruct(m, "JWTMeta")
+}
+
+func (m *JWTMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = m.CryptoMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
+	_, _ = fmt.Fprintf(w, ", audience=%s", m.Audience)
+	_, _ = fmt.Fprintf(w, ", alg=%s", m.JWSAlgorithm)
+	_, _ = fmt.Fprintf(w, ", kid=%s", m.KeyID)
+	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
+	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
+	_, _ = fmt.Fprintf(w, ", issued_at=%d", m.IssuedAt)
+	_, _ = fmt.Fprintf(w, ", claims=%v", m.Claims)
+
+	return 0, nil
+}
+
+func (m *JWTMeta) IssuedAtClaim() time.Time {
+	return time.Unix(m.IssuedAt, 0)
+}
+
+func (m *JWTMeta) NotBeforeClaim() time.Time {
+	return time.Unix(m.ValidAt, 0)
+}
+
+func (m *JWTMeta) ExpirationClaim() time.Time {
+	return time.Unix(m.ValidAt+m.ValidFor, 0)
+}
+
+func (m *JWTMeta) SubjectClaim() string {
+	return m.Subject
+}
+
+func (m *JWTMeta) IssuerClaim() string {
+	return m.Organization
+}
+
+func (m *JWTMeta) SignatureAlgorithm() jwa.SignatureAlgorithm {
+	if m.JWSAlgorithm != crypto.JWSAlgorithmNone {
+		return m.JWSAlgorithm.SignatureAlgorithm()
+	}
+
+	if m.Algorithm == crypto.AlgorithmECDSA {
+		return m.ECDSACurve.SignatureAlgorithm()
+	}
+
+	return m.Algorithm.SignatureAlgorithm()
+}
+
+type JWTRotationMeta struct {
+	JWTMeta `json:",inline"`
+
+	GracePeriod int64 `json:"grace_period"`
+}
+
+func ParseJWTRotationMeta(issuer string, start time.Time, r *nethttp.Request) (*JWTRotationMeta, error) {
+	meta, err := ParseJWTMeta(issuer, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	gracePeriod, err := http.ParseFormUint(r, "grace_period", 60*60*24) // 1 day
+	if err != nil {
+		return nil, err
+	}
+
+	if gracePeriod > MaxJWTGracePeriod {
+		return nil, fmt.Errorf("grace_period must not exceed %d, got %d", MaxJWTGracePeriod, gracePeriod)
+	}
+
+	result := &JWTRotationMeta{
+		JWTMeta:     *meta,
+		GracePeriod: int64(gracePeriod),
+	}
+
+	return result, nil
+}
+
+func (m *JWTRotationMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *JWTRotationMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int64("grace_period", m.GracePeriod),
+	}
+
+	return append(attrs, m.JWTMeta.LogAttrs()...)
+}
+
+func (m *JWTRotationMeta) String() string {
+	return DescribeStruct(m, "JWTRotationMeta")
+}
+
+func (m *JWTRotationMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.JWTMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", grace_period=%d", m.GracePeriod)
+
+	return 0, nil
+}
+
+func (m *JWTRotationMeta) GracePeriodDuration() time.Duration {
+	return time.Duration(m.GracePeriod) * time.Second
+}
+
+type JWTTokenMeta struct {
+	JWTMeta `json:",inline"`
+
+	Encryption        crypto.JWEAlgorithm  `json:"encryption,omitempty"`
+	ContentEncryption crypto.JWEEncryption `json:"content_encryption,omitempty"`
+	Nested            bool                 `json:"nested,omitempty"`
+	RecipientCrypto   *CryptoMeta          `json:"recipient_crypto,omitempty"`
+}
+
+func ParseJWTTokenMeta(issuer string, start time.Time, r *nethttp.Request) (*JWTTokenMeta, error) {
+	meta, err := ParseJWTMeta(issuer, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := http.ParseFormJWEAlgorithm(r, "encryption", crypto.JWEAlgorithmNone)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &JWTTokenMeta{
+		JWTMeta:    *meta,
+		Encryption: encryption,
+	}
+
+	if encryption == crypto.JWEAlgorithmNone {
+		return result, nil
+	}
+
+	result.ContentEncryption, err = http.ParseFormJWEEncryption(r, "content_encryption", crypto.JWEEncryptionA256GCM)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Nested, err = http.ParseFormBool(r, "nested", false)
+	if err != nil {
+		return nil, err
+	}
+
+	recipient := http.ParseFormString(r, "recipient", meta.Subject)
+	defaults := &CryptoMeta{
+		Length:     meta.Length,
+		Algorithm:  crypto.AlgorithmRSA,
+		ECDSACurve: crypto.ECDSACurveP256,
+	}
+
+	result.RecipientCrypto, err = parseCryptoMeta(recipient, "recipient_", defaults, r)
+	if err != nil {
+		return nil, err
+	}
+
+	if algo := encryption.KeyAlgorithm(); algo != 0 {
+		result.RecipientCrypto.Algorithm = algo
+	}
+
+	if encryption.KeyAlgorithm() == crypto.AlgorithmECDSA && result.RecipientCrypto.ECDSACurve.ECDHCurve() == nil {
+		return nil, fmt.Errorf("unsupported ECDH curve: %s", result.RecipientCrypto.ECDSACurve)
+	}
+
+	return result, nil
+}
+
+func (m *JWTTokenMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *JWTTokenMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Any("encryption", m.Encryption),
+		slog.Any("content_encryption", m.ContentEncryption),
+		slog.Bool("nested", m.Nested),
+	}
+
+	if m.RecipientCrypto != nil {
+		attrs = append(attrs, slog.Any("recipient_crypto", m.RecipientCrypto))
+	}
+
+	return append(attrs, m.JWTMeta.LogAttrs()...)
+}
+
+func (m *JWTTokenMeta) String() string {
+	return DescribeStruct(m, "JWTTokenMeta")
+}
+
+func (m *JWTTokenMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.JWTMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", encryption=%s", m.Encryption)
+	_, _ = fmt.Fprintf(w, ", content_encryption=%s", m.ContentEncryption)
+	_, _ = fmt.Fprintf(w, ", nested=%t", m.Nested)
+
+	if m.RecipientCrypto != nil {
+		_, _ = fmt.Fprintf(w, ", recipient_crypto=%s", m.RecipientCrypto)
+	}
+
+	return 0, nil
+}
+
+type OAuth2TokenMeta struct {
+	JWTMeta `json:",inline"`
+
+	GrantType    string `json:"grant_type"`
+	ClientID     string `json:"client_id,omitempty"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Code         string `json:"code,omitempty"`
+	RedirectURI  string `json:"redirect_uri,omitempty"`
+	CodeVerifier string `json:"code_verifier,omitempty"`
+}
+
+func ParseOAuth2TokenMeta(issuer string, start time.Time, r *nethttp.Request) (*OAuth2TokenMeta, error) {
+	meta, err := ParseJWTMeta(issuer, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = http.ParseFormString(r, "client_id", "")
+	}
+
+	result := &OAuth2TokenMeta{
+		JWTMeta:      *meta,
+		GrantType:    http.ParseFormString(r, "grant_type", ""),
+		ClientID:     clientID,
+		Username:     http.ParseFormString(r, "username", ""),
+		Password:     http.ParseFormString(r, "password", ""),
+		Scope:        http.ParseFormString(r, "scope", ""),
+		RefreshToken: http.ParseFormString(r, "refresh_token", ""),
+		Code:         http.ParseFormString(r, "code", ""),
+		RedirectURI:  http.ParseFormString(r, "redirect_uri", ""),
+		CodeVerifier: http.ParseFormString(r, "code_verifier", ""),
+	}
+
+	return result, nil
+}
+
+func (m *OAuth2TokenMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *OAuth2TokenMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("grant_type", m.GrantType),
+		slog.String("client_id", m.ClientID),
+		slog.String("username", m.Username),
+		slog.String("password", m.Password),
+		slog.String("scope", m.Scope),
+		slog.String("refresh_token", m.RefreshToken),
+		slog.String("code", m.Code),
+		slog.String("redirect_uri", m.RedirectURI),
+		slog.String("code_verifier", m.CodeVerifier),
+	}
+
+	return append(attrs, m.JWTMeta.LogAttrs()...)
+}
+
+func (m *OAuth2TokenMeta) String() string {
+	return DescribeStruct(m, "OAuth2TokenMeta")
+}
+
+func (m *OAuth2TokenMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.JWTMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", grant_type=%s", m.GrantType)
+	_, _ = fmt.Fprintf(w, ", client_id=%s", m.ClientID)
+	_, _ = fmt.Fprintf(w, ", username=%s", m.Username)
+	_, _ = fmt.Fprintf(w, ", password=%s", m.Password)
+	_, _ = fmt.Fprintf(w, ", scope=%s", m.Scope)
+	_, _ = fmt.Fprintf(w, ", refresh_token=%s", m.RefreshToken)
+	_, _ = fmt.Fprintf(w, ", code=%s", m.Code)
+	_, _ = fmt.Fprintf(w, ", redirect_uri=%s", m.RedirectURI)
+	_, _ = fmt.Fprintf(w, ", code_verifier=%s", m.CodeVerifier)
+
+	return 0, nil
+}
+
+type OAuth2AuthorizeMeta struct {
+	JWTMeta `json:",inline"`
+
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope,omitempty"`
+	State               string `json:"state,omitempty"`
+	Nonce               string `json:"nonce,omitempty"`
+	CodeChallenge       string `json:"code_challenge,omitempty"`
+	CodeChallengeMethod string `json:"code_challenge_method,omitempty"`
+	LoginHint           string `json:"login_hint,omitempty"`
+	Prompt              string `json:"prompt,omitempty"`
+}
+
+func ParseOAuth2AuthorizeMeta(issuer string, start time.Time, r *nethttp.Request) (*OAuth2AuthorizeMeta, error) {
+	meta, err := ParseJWTMeta(issuer, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := http.ParseFormString(r, "client_id", "")
+	if clientID == "" {
+		return nil, ErrNoClientID
+	}
+
+	redirectURI := http.ParseFormString(r, "redirect_uri", "")
+	if u, err := url.Parse(redirectURI); err != nil || !u.IsAbs() {
+		return nil, ErrInvalidRedirectURI
+	}
+
+	challenge := http.ParseFormString(r, "code_challenge", "")
+	challengeMethod := ""
+	if challenge != "" {
+		challengeMethod = http.ParseFormString(r, "code_challenge_method", "plain")
+	}
+
+	result := &OAuth2AuthorizeMeta{
+		JWTMeta:             *meta,
+		ResponseType:        http.ParseFormString(r, "response_type", ""),
+		ClientID:            clientID,
+		RedirectURI:         redirectURI,
+		Scope:               http.ParseFormString(r, "scope", ""),
+		State:               http.ParseFormString(r, "state", ""),
+		Nonce:               http.ParseFormString(r, "nonce", ""),
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: challengeMethod,
+		LoginHint:           http.ParseFormString(r, "login_hint", ""),
+		Prompt:              http.ParseFormString(r, "prompt", ""),
+	}
+
+	return result, nil
+}
+
+func (m *OAuth2AuthorizeMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *OAuth2AuthorizeMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("response_type", m.ResponseType),
+		slog.String("client_id", m.ClientID),
+		slog.String("redirect_uri", m.RedirectURI),
+		slog.String("scope", m.Scope),
+		slog.String("state", m.State),
+		slog.String("nonce", m.Nonce),
+		slog.String("code_challenge", m.CodeChallenge),
+		slog.String("code_challenge_method", m.CodeChallengeMethod),
+		slog.String("login_hint", m.LoginHint),
+		slog.String("prompt", m.Prompt),
+	}
+
+	return append(attrs, m.JWTMeta.LogAttrs()...)
+}
+
+func (m *OAuth2AuthorizeMeta) String() string {
+	return DescribeStruct(m, "OAuth2AuthorizeMeta")
+}
+
+func (m *OAuth2AuthorizeMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.JWTMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", response_type=%s", m.ResponseType)
+	_, _ = fmt.Fprintf(w, ", client_id=%s", m.ClientID)
+	_, _ = fmt.Fprintf(w, ", redirect_uri=%s", m.RedirectURI)
+	_, _ = fmt.Fprintf(w, ", scope=%s", m.Scope)
+	_, _ = fmt.Fprintf(w, ", state=%s", m.State)
+	_, _ = fmt.Fprintf(w, ", nonce=%s", m.Nonce)
+	_, _ = fmt.Fprintf(w, ", code_challenge=%s", m.CodeChallenge)
+	_, _ = fmt.Fprintf(w, ", code_challenge_method=%s", m.CodeChallengeMethod)
+	_, _ = fmt.Fprintf(w, ", login_hint=%s", m.LoginHint)
+	_, _ = fmt.Fprintf(w, ", prompt=%s", m.Prompt)
+
+	return 0, nil
+}
+
+type OAuth2TokenReferenceMeta struct {
+	JWTMeta `json:",inline"`
+
+	Token         string `json:"token"`
+	TokenTypeHint string `json:"token_type_hint,omitempty"`
+}
+
+func ParseOAuth2TokenReferenceMeta(issuer string, start time.Time, r *nethttp.Request) (*OAuth2TokenReferenceMeta, error) {
+	meta, err := ParseJWTMeta(issuer, start, r)
+	if err != nil {
+		return nil, err
+	}
+
+	token := http.ParseFormString(r, "token", "")
+	if token == "" {
+		return nil, ErrNoToken
+	}
+
+	result := &OAuth2TokenReferenceMeta{
+		JWTMeta:       *meta,
+		Token:         token,
+		TokenTypeHint: http.ParseFormString(r, "token_type_hint", ""),
+	}
+
+	return result, nil
+}
+
+func (m *OAuth2TokenReferenceMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *OAuth2TokenReferenceMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("token", m.Token),
+		slog.String("token_type_hint", m.TokenTypeHint),
+	}
+
+	return append(attrs, m.JWTMeta.LogAttrs()...)
+}
+
+func (m *OAuth2TokenReferenceMeta) String() string {
+	return DescribeStruct(m, "OAuth2TokenReferenceMeta")
+}
+
+func (m *OAuth2TokenReferenceMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.JWTMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", token=%s", m.Token)
+	_, _ = fmt.Fprintf(w, ", token_type_hint=%s", m.TokenTypeHint)
+
+	return 0, nil
+}
+
+type JWTVerifyMeta struct {
+	StaticMeta `json:",inline"`
+
+	Token    string `json:"token"`
+	Audience string `json:"audience,omitempty"`
+	VerifyAt int64  `json:"verify_at"`
+}
+
+func ParseJWTVerifyMeta(now time.Time, r *nethttp.Request) (*JWTVerifyMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	verifyAt, err := http.ParseFormInt(r, "verify_at", now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	token := http.ParseFormString(r, "token", "")
+	if token == "" {
+		return nil, ErrNoToken
+	}
+
+	static := NewStaticMeta(r)
+	result := &JWTVerifyMeta{
+		StaticMeta: *static,
+		Token:      token,
+		Audience:   http.ParseFormString(r, "audience", ""),
+		VerifyAt:   verifyAt,
+	}
+
+	return result, nil
+}
+
+func (m *JWTVerifyMeta) VerifyTime() time.Time {
+	return time.Unix(m.VerifyAt, 0)
+}
+
+func (m *JWTVerifyMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *JWTVerifyMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("token", m.Token),
+		slog.String("audience", m.Audience),
+		slog.Int64("verify_at", m.VerifyAt),
+	}
+
+	return append(attrs, m.StaticMeta.LogAttrs()...)
+}
+
+func (m *JWTVerifyMeta) String() string {
+	return DescribeStruct(m, "JWTVerifyMeta")
+}
+
+func (m *JWTVerifyMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", token=%s", m.Token)
+	_, _ = fmt.Fprintf(w, ", audience=%s", m.Audience)
+	_, _ = fmt.Fprintf(w, ", verify_at=%d", m.VerifyAt)
+
+	return 0, nil
+}
+
+type OTPMeta struct {
+	StaticMeta `json:",inline"`
+
+	Organization string `json:"organization,omitempty"`
+	Subject      string `json:"subject,omitempty"`
+
+	Length int `json:"length,omitempty"`
+
+	Algorithm hash.Algorithm   `json:"algorithm,omitempty"`
+	Digits    crypto.OTPDigits `json:"digits,omitempty"`
+}
+
+func ParseOTPMeta(subject string, r *nethttp.Request) (*OTPMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := http.ParseFormInt(r, "length", 20)
+	if err != nil {
+		return nil, err
+	}
+
+	algo, err := http.ParseFormHashAlgorithm(r, "algorithm", hash.AlgorithmSHA1)
+	if err != nil {
+		return nil, err
+	}
+
+	digits, err := http.ParseFormOTPDigits(r, "digits", crypto.OTPDigitsSix)
+	if err != nil {
+		return nil, err
+	}
+
+	if length <= 0 {
+		return nil, fmt.Errorf("length must be a positive value, got %d", length)
+	}
+
+	organization := http.ParseFormString(r, "organization", "Vault")
+	static := NewStaticMeta(r)
+	result := &OTPMeta{
+		StaticMeta:   *static,
+		Organization: organization,
+		Subject:      subject,
+		Length:       int(length),
+		Algorithm:    algo,
+		Digits:       digits,
+	}
+
+	return result, nil
+}
+
+func (m *OTPMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *OTPMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("organization", m.Organization),
+		slog.String("subject", m.Subject),
+		slog.Int("length", m.Length),
+		slog.Any("digits", m.Digits),
+	}
+
+	return append(attrs, m.StaticMeta.LogAttrs()...)
+}
+
+func (m *OTPMeta) String() string {
+	return DescribeStruct(m, "OTPMeta")
+}
+
+func (m *OTPMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", organization=%s", m.Organization)
+	_, _ = fmt.Fprintf(w, ", subject=%s", m.Subject)
+	_, _ = fmt.Fprintf(w, ", length=%d", m.Length)
+	_, _ = fmt.Fprintf(w, ", digits=%s", m.Digits)
+
+	return 0, nil
+}
+
+type QRCodeMeta struct {
+	Format   string `json:"format"`
+	Encoding string `json:"encoding"`
+	Size     int    `json:"size"`
+}
+
+func ParseQRCodeMeta(r *nethttp.Request) (*QRCodeMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := http.ParseFormInt(r, "size", 200)
+	if err != nil {
+		return nil, err
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("size must be a positive value, got %d", size)
+	}
+
+	if size > MaxQRCodeSize {
+		return nil, fmt.Errorf("size must not exceed %d, got %d", MaxQRCodeSize, size)
+	}
+
+	format := http.ParseFormString(r, "format", "png")
+	if format != "png" && format != "svg" {
+		return nil, fmt.Errorf("invalid QR code format %q", format)
+	}
+
+	encoding := http.ParseFormString(r, "encoding", "raw")
+	if encoding != "raw" && encoding != "base64" {
+		return nil, fmt.Errorf("invalid QR code encoding %q", encoding)
+	}
+
+	result := &QRCodeMeta{
+		Format:   format,
+		Encoding: encoding,
+		Size:     int(size),
+	}
+
+	return result, nil
+}
+
+func (m *QRCodeMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *QRCodeMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("format", m.Format),
+		slog.String("encoding", m.Encoding),
+		slog.Int("size", m.Size),
+	}
+
+	return attrs
+}
+
+func (m *QRCodeMeta) String() string {
+	return DescribeStruct(m, "QRCodeMeta")
+}
+
+func (m *QRCodeMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = fmt.Fprintf(w, ", format=%s", m.Format)
+	_, _ = fmt.Fprintf(w, ", encoding=%s", m.Encoding)
+	_, _ = fmt.Fprintf(w, ", size=%d", m.Size)
+
+	return 0, nil
+}
+
+type TOTPMeta struct {
+	StaticMeta `json:",inline"`
+	OTPMeta    `json:",inline"`
+
+	ValidAt  int64 `json:"valid_at,omitempty"`
+	ValidFor int64 `json:"valid_for,omitempty"`
+}
+
+func ParseTOTPMeta(subject string, r *nethttp.Request) (*TOTPMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := ParseOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	validAt, err := http.ParseFormInt(r, "valid_at", time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	validFor, err := http.ParseFormInt(r, "valid_for", 30) // 30 seconds
+	if err != nil {
+		return nil, err
+	}
+
+	if validFor <= 0 {
+		return nil, fmt.Errorf("valid_for must be a positive value, got %d", validFor)
+	}
+
+	static := NewStaticMeta(r)
+	result := &TOTPMeta{
+		StaticMeta: *static,
+		OTPMeta:    *shared,
+		ValidAt:    validAt,
+		ValidFor:   validFor,
+	}
+
+	return result, nil
+}
+
+func (m *TOTPMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TOTPMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.Int64("valid_at", m.ValidAt),
+		slog.Int64("valid_for", m.ValidFor),
+	}
+	attrs = append(attrs, m.StaticMeta.LogAttrs()...)
+
+	return append(attrs, m.OTPMeta.LogAttrs()...)
+}
+
+func (m *TOTPMeta) String() string {
+	return DescribeStruct(m, "TOTPMeta")
+}
+
+func (m *TOTPMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = m.OTPMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", valid_at=%d", m.ValidAt)
+	_, _ = fmt.Fprintf(w, ", valid_for=%d", m.ValidFor)
+
+	return 0, nil
+}
+
+type TOTPVerifyMeta struct {
+	TOTPMeta `json:",inline"`
+
+	Code string `json:"code"`
+	Skew uint64 `json:"skew"`
+}
+
+func ParseTOTPVerifyMeta(subject string, r *nethttp.Request) (*TOTPVerifyMeta, error) {
+	meta, err := ParseTOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	code := http.ParseFormString(r, "code", "")
+	if code == "" {
+		return nil, ErrNoOTPCode
+	}
+
+	skew, err := http.ParseFormUint(r, "skew", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if skew > MaxTOTPSkew {
+		return nil, fmt.Errorf("skew must not exceed %d, got %d", MaxTOTPSkew, skew)
+	}
+
+	result := &TOTPVerifyMeta{
+		TOTPMeta: *meta,
+		Code:     code,
+		Skew:     skew,
+	}
+
+	return result, nil
+}
+
+func (m *TOTPVerifyMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TOTPVerifyMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("code", m.Code),
+		slog.Uint64("skew", m.Skew),
+	}
+
+	return append(attrs, m.TOTPMeta.LogAttrs()...)
+}
+
+func (m *TOTPVerifyMeta) String() string {
+	return DescribeStruct(m, "TOTPVerifyMeta")
+}
+
+func (m *TOTPVerifyMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TOTPMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", code=%s", m.Code)
+	_, _ = fmt.Fprintf(w, ", skew=%d", m.Skew)
+
+	return 0, nil
+}
+
+type TOTPQRCodeMeta struct {
+	TOTPMeta   `json:",inline"`
+	QRCodeMeta `json:",inline"`
+}
+
+func ParseTOTPQRCodeMeta(subject string, r *nethttp.Request) (*TOTPQRCodeMeta, error) {
+	meta, err := ParseTOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := ParseQRCodeMeta(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TOTPQRCodeMeta{
+		TOTPMeta:   *meta,
+		QRCodeMeta: *image,
+	}
+
+	return result, nil
+}
+
+func (m *TOTPQRCodeMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *TOTPQRCodeMeta) LogAttrs() []slog.Attr {
+	attrs := m.QRCodeMeta.LogAttrs()
+
+	return append(attrs, m.TOTPMeta.LogAttrs()...)
+}
+
+func (m *TOTPQRCodeMeta) String() string {
+	return DescribeStruct(m, "TOTPQRCodeMeta")
+}
+
+func (m *TOTPQRCodeMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.TOTPMeta.StructWriteTo(w)
+	_, _ = m.QRCodeMeta.StructWriteTo(w)
+
+	return 0, nil
+}
+
+type HOTPMeta struct {
+	StaticMeta `json:",inline"`
+	OTPMeta    `json:",inline"`
 
 	Counter int64 `json:"counter,omitempty"`
 }
@@ -806,13 +2351,13 @@ func ParseHOTPMeta(subject string, r *nethttp.Request) (*HOTPMeta, error) {
 		return nil, err
 	}
 
-	counter, err := http.ParseFormInt(r, "counter", 1)
+	counter, err := http.ParseFormInt(r, "counter", 0) // server-side counter
 	if err != nil {
 		return nil, err
 	}
 
-	if counter <= 0 {
-		return nil, fmt.Errorf("counter must be a positive value, got %d", counter)
+	if counter < 0 {
+		return nil, fmt.Errorf("counter must not be a negative value, got %d", counter)
 	}
 
 	static := NewStaticMeta(r)
@@ -849,3 +2394,392 @@ func (m *HOTPMeta) StructWriteTo(w io.Writer) (int, error) {
 
 	return 0, nil
 }
+
+type HOTPVerifyMeta struct {
+	HOTPMeta `json:",inline"`
+
+	Code   string `json:"code"`
+	Window uint64 `json:"window"`
+}
+
+func ParseHOTPVerifyMeta(subject string, r *nethttp.Request) (*HOTPVerifyMeta, error) {
+	meta, err := ParseHOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	code := http.ParseFormString(r, "code", "")
+	if code == "" {
+		return nil, ErrNoOTPCode
+	}
+
+	window, err := http.ParseFormUint(r, "window", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if window > MaxHOTPWindow {
+		return nil, fmt.Errorf("window must not exceed %d, got %d", MaxHOTPWindow, window)
+	}
+
+	result := &HOTPVerifyMeta{
+		HOTPMeta: *meta,
+		Code:     code,
+		Window:   window,
+	}
+
+	return result, nil
+}
+
+func (m *HOTPVerifyMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *HOTPVerifyMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("code", m.Code),
+		slog.Uint64("window", m.Window),
+	}
+
+	return append(attrs, m.HOTPMeta.LogAttrs()...)
+}
+
+func (m *HOTPVerifyMeta) String() string {
+	return DescribeStruct(m, "HOTPVerifyMeta")
+}
+
+func (m *HOTPVerifyMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.HOTPMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", code=%s", m.Code)
+	_, _ = fmt.Fprintf(w, ", window=%d", m.Window)
+
+	return 0, nil
+}
+
+type HOTPResyncMeta struct {
+	HOTPMeta `json:",inline"`
+
+	Code     string `json:"code"`
+	NextCode string `json:"next_code"`
+	Window   uint64 `json:"window"`
+}
+
+func ParseHOTPResyncMeta(subject string, r *nethttp.Request) (*HOTPResyncMeta, error) {
+	meta, err := ParseHOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	code := http.ParseFormString(r, "code", "")
+	nextCode := http.ParseFormString(r, "next_code", "")
+	if code == "" || nextCode == "" {
+		return nil, ErrNoOTPResyncCodes
+	}
+
+	window, err := http.ParseFormUint(r, "window", 100)
+	if err != nil {
+		return nil, err
+	}
+
+	if window > MaxHOTPWindow {
+		return nil, fmt.Errorf("window must not exceed %d, got %d", MaxHOTPWindow, window)
+	}
+
+	result := &HOTPResyncMeta{
+		HOTPMeta: *meta,
+		Code:     code,
+		NextCode: nextCode,
+		Window:   window,
+	}
+
+	return result, nil
+}
+
+func (m *HOTPResyncMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *HOTPResyncMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("code", m.Code),
+		slog.String("next_code", m.NextCode),
+		slog.Uint64("window", m.Window),
+	}
+
+	return append(attrs, m.HOTPMeta.LogAttrs()...)
+}
+
+func (m *HOTPResyncMeta) String() string {
+	return DescribeStruct(m, "HOTPResyncMeta")
+}
+
+func (m *HOTPResyncMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.HOTPMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", code=%s", m.Code)
+	_, _ = fmt.Fprintf(w, ", next_code=%s", m.NextCode)
+	_, _ = fmt.Fprintf(w, ", window=%d", m.Window)
+
+	return 0, nil
+}
+
+type HOTPQRCodeMeta struct {
+	HOTPMeta   `json:",inline"`
+	QRCodeMeta `json:",inline"`
+}
+
+func ParseHOTPQRCodeMeta(subject string, r *nethttp.Request) (*HOTPQRCodeMeta, error) {
+	meta, err := ParseHOTPMeta(subject, r)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := ParseQRCodeMeta(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HOTPQRCodeMeta{
+		HOTPMeta:   *meta,
+		QRCodeMeta: *image,
+	}
+
+	return result, nil
+}
+
+func (m *HOTPQRCodeMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *HOTPQRCodeMeta) LogAttrs() []slog.Attr {
+	attrs := m.QRCodeMeta.LogAttrs()
+
+	return append(attrs, m.HOTPMeta.LogAttrs()...)
+}
+
+func (m *HOTPQRCodeMeta) String() string {
+	return DescribeStruct(m, "HOTPQRCodeMeta")
+}
+
+func (m *HOTPQRCodeMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.HOTPMeta.StructWriteTo(w)
+	_, _ = m.QRCodeMeta.StructWriteTo(w)
+
+	return 0, nil
+}
+
+type MFARecoveryCodesMeta struct {
+	StaticMeta `json:",inline"`
+
+	Subject string `json:"subject,omitempty"`
+
+	Count     int    `json:"count,omitempty"`
+	Groups    int    `json:"groups,omitempty"`
+	GroupSize int    `json:"group_size,omitempty"`
+	Separator string `json:"separator"`
+	Alphabet  string `json:"alphabet,omitempty"`
+
+	Hash       crypto.PasswordHash `json:"hash,omitempty"`
+	Regenerate bool                `json:"regenerate"`
+}
+
+func ParseMFARecoveryCodesMeta(subject string, r *nethttp.Request) (*MFARecoveryCodesMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := http.ParseFormInt(r, "count", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := http.ParseFormInt(r, "groups", 2)
+	if err != nil {
+		return nil, err
+	}
+
+	groupSize, err := http.ParseFormInt(r, "group_size", 4)
+	if err != nil {
+		return nil, err
+	}
+
+	upper, err := http.ParseFormBool(r, "upper", false)
+	if err != nil {
+		return nil, err
+	}
+
+	lower, err := http.ParseFormBool(r, "lower", false)
+	if err != nil {
+		return nil, err
+	}
+
+	numeric, err := http.ParseFormBool(r, "numeric", false)
+	if err != nil {
+		return nil, err
+	}
+
+	digest, err := http.ParseFormPasswordHash(r, "hash", crypto.PasswordHashBcrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	regenerate, err := http.ParseFormBool(r, "regenerate", false)
+	if err != nil {
+		return nil, err
+	}
+
+	if count <= 0 {
+		return nil, fmt.Errorf("count must be a positive value, got %d", count)
+	}
+
+	if groups <= 0 {
+		return nil, fmt.Errorf("groups must be a positive value, got %d", groups)
+	}
+
+	if groupSize <= 0 {
+		return nil, fmt.Errorf("group_size must be a positive value, got %d", groupSize)
+	}
+
+	if count > MaxRecoveryCodes {
+		return nil, fmt.Errorf("count must not exceed %d, got %d", MaxRecoveryCodes, count)
+	}
+
+	if groups > MaxRecoveryCodeLength || groupSize > MaxRecoveryCodeLength {
+		return nil, fmt.Errorf("recovery codes must not exceed %d characters, got %dx%d", MaxRecoveryCodeLength, groups, groupSize)
+	}
+
+	separator := http.ParseFormString(r, "separator", "-")
+	if length := groups*groupSize + (groups-1)*int64(len(separator)); length > MaxRecoveryCodeLength {
+		return nil, fmt.Errorf("recovery codes must not exceed %d characters, got %d", MaxRecoveryCodeLength, length)
+	}
+
+	if !upper && !lower && !numeric {
+		lower = true
+		numeric = true
+	}
+
+	alphabet := http.ParseFormString(r, "alphabet", string(generateRandomPool(upper, lower, numeric, false)))
+	// repeated characters would inflate the number of distinct codes
+	var seen [utf8.RuneSelf]bool
+	pool := make([]byte, 0, len(alphabet))
+	for _, c := range alphabet {
+		if c >= utf8.RuneSelf {
+			return nil, fmt.Errorf("alphabet must only contain ASCII characters, got %q", c)
+		}
+
+		if !seen[c] {
+			seen[c] = true
+			pool = append(pool, byte(c))
+		}
+	}
+
+	alphabet = string(pool)
+	if math.Pow(float64(len(alphabet)), float64(groups*groupSize)) < float64(count) {
+		return nil, fmt.Errorf("alphabet of %d characters cannot produce %d distinct codes", len(alphabet), count)
+	}
+
+	static := NewStaticMeta(r)
+	result := &MFARecoveryCodesMeta{
+		StaticMeta: *static,
+		Subject:    subject,
+		Count:      int(count),
+		Groups:     int(groups),
+		GroupSize:  int(groupSize),
+		Separator:  separator,
+		Alphabet:   alphabet,
+		Hash:       digest,
+		Regenerate: regenerate,
+	}
+
+	return result, nil
+}
+
+func (m *MFARecoveryCodesMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *MFARecoveryCodesMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("subject", m.Subject),
+		slog.Int("count", m.Count),
+		slog.Int("groups", m.Groups),
+		slog.Int("group_size", m.GroupSize),
+		slog.String("separator", m.Separator),
+		slog.Int("alphabet", len(m.Alphabet)),
+		slog.Any("hash", m.Hash),
+		slog.Bool("regenerate", m.Regenerate),
+	}
+
+	return append(attrs, m.StaticMeta.LogAttrs()...)
+}
+
+func (m *MFARecoveryCodesMeta) String() string {
+	return DescribeStruct(m, "MFARecoveryCodesMeta")
+}
+
+func (m *MFARecoveryCodesMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", subject=%s", m.Subject)
+	_, _ = fmt.Fprintf(w, ", count=%d", m.Count)
+	_, _ = fmt.Fprintf(w, ", groups=%d", m.Groups)
+	_, _ = fmt.Fprintf(w, ", group_size=%d", m.GroupSize)
+	_, _ = fmt.Fprintf(w, ", separator=%q", m.Separator)
+	_, _ = fmt.Fprintf(w, ", alphabet=%q", m.Alphabet)
+	_, _ = fmt.Fprintf(w, ", hash=%s", m.Hash)
+	_, _ = fmt.Fprintf(w, ", regenerate=%t", m.Regenerate)
+
+	return 0, nil
+}
+
+type MFARedeemMeta struct {
+	StaticMeta `json:",inline"`
+
+	Subject string `json:"subject,omitempty"`
+	Code    string `json:"code"`
+}
+
+func ParseMFARedeemMeta(subject string, r *nethttp.Request) (*MFARedeemMeta, error) {
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+
+	code := strings.TrimSpace(http.ParseFormString(r, "code", ""))
+	if code == "" {
+		return nil, ErrNoRecoveryCode
+	}
+
+	static := NewStaticMeta(r)
+	result := &MFARedeemMeta{
+		StaticMeta: *static,
+		Subject:    subject,
+		Code:       code,
+	}
+
+	return result, nil
+}
+
+func (m *MFARedeemMeta) LogValue() slog.Value {
+	return slog.GroupValue(m.LogAttrs()...)
+}
+
+func (m *MFARedeemMeta) LogAttrs() []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String("subject", m.Subject),
+		slog.String("code", m.Code),
+	}
+
+	return append(attrs, m.StaticMeta.LogAttrs()...)
+}
+
+func (m *MFARedeemMeta) String() string {
+	return DescribeStruct(m, "MFARedeemMeta")
+}
+
+func (m *MFARedeemMeta) StructWriteTo(w io.Writer) (int, error) {
+	_, _ = m.StaticMeta.StructWriteTo(w)
+	_, _ = fmt.Fprintf(w, ", subject=%s", m.Subject)
+	_, _ = fmt.Fprintf(w, ", code=%s", m.Code)
+
+	return 0, nil
+}
@@ -0,0 +1,190 @@
+package fake
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	nethttp "net/http"
+	"slices"
+	"strings"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+var (
+	ErrNoRecoveryCode         = errors.New("code is required")
+	ErrNoRecoveryCodes        = errors.New("no recovery codes have been issued")
+	ErrRecoveryCodesExhausted = errors.New("unable to generate enough distinct recovery codes")
+
+	MaxRecoveryCodes      int64 = 100
+	MaxRecoveryCodeLength int64 = 64
+	MaxRecoveryCodeDraws        = 10000
+)
+
+type MFAHandler struct {
+	logger *slog.Logger
+	rnd    *rand.Rand
+	sets   cache.Store[string, *mfaRecoverySet]
+}
+
+type mfaRecoverySet struct {
+	format string
+	codes  []mfaRecoveryCode
+}
+
+type mfaRecoveryCode struct {
+	Code string `json:"code"`
+	Hash string `json:"hash"`
+	Used bool   `json:"used"`
+}
+
+type mfaRedemption struct {
+	Valid     bool `json:"valid"`
+	Remaining int  `json:"remaining"`
+}
+
+func NewMFAHandler(rnd *rand.Rand, logger *slog.Logger) *MFAHandler {
+	sets := cache.NewStore[string, *mfaRecoverySet]()
+	result := &MFAHandler{
+		logger: logger,
+		rnd:    rnd,
+		sets:   sets,
+	}
+
+	return result
+}
+
+func (h *MFAHandler) RouteRecoveryCodes(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("mfa", "{account}", "recovery-codes"), h.ServeRecoveryCodes
+}
+
+func (h *MFAHandler) ServeRecoveryCodes(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseMFARecoveryCodesMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving MFA recovery codes", "meta", meta)
+
+	format := mfaRecoveryFormat(meta)
+	set, ok := h.sets.Get(meta.Subject)
+	if !ok || meta.Regenerate || set.format != format {
+		// hashing is expensive, so the set is generated without holding the store lock
+		generated, err := h.generateRecoverySet(meta, format)
+		if err != nil {
+			http.ServeError(w, nethttp.StatusInternalServerError, err)
+			return
+		}
+
+		set, _ = h.sets.Update(meta.Subject, func(current *mfaRecoverySet, ok bool) (*mfaRecoverySet, error) {
+			if ok && !meta.Regenerate && current.format == format {
+				return current, nil
+			}
+
+			return generated, nil
+		})
+	}
+
+	http.ServeSecretObject(w, set.codes, meta)
+}
+
+func (h *MFAHandler) RouteRedeem(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("mfa", "{account}", "recovery-codes", "redeem"), h.ServeRedeem
+}
+
+func (h *MFAHandler) ServeRedeem(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseMFARedeemMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("redeeming MFA recovery code", "meta", meta)
+
+	data := &mfaRedemption{}
+	_, err = h.sets.Update(meta.Subject, func(set *mfaRecoverySet, ok bool) (*mfaRecoverySet, error) {
+		if !ok {
+			return nil, ErrNoRecoveryCodes
+		}
+
+		// previously served sets might still be in use, so never modify them in place
+		codes := slices.Clone(set.codes)
+		for i := range codes {
+			if !codes[i].Used && codes[i].Code == meta.Code {
+				codes[i].Used = true
+				data.Valid = true
+			}
+
+			if !codes[i].Used {
+				data.Remaining++
+			}
+		}
+
+		result := &mfaRecoverySet{
+			format: set.format,
+			codes:  codes,
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		http.ServeError(w, nethttp.StatusNotFound, err)
+		return
+	}
+
+	http.ServeJSON(w, data)
+}
+
+func (h *MFAHandler) generateRecoverySet(meta *MFARecoveryCodesMeta, format string) (*mfaRecoverySet, error) {
+	pool := []byte(meta.Alphabet)
+	seen := make(map[string]bool, meta.Count)
+	codes := make([]mfaRecoveryCode, 0, meta.Count)
+
+	for draws := 0; len(codes) < meta.Count; draws++ {
+		if draws >= MaxRecoveryCodeDraws {
+			return nil, ErrRecoveryCodesExhausted
+		}
+
+		code := generateRecoveryCode(h.rnd, meta.Groups, meta.GroupSize, meta.Separator, pool)
+		if seen[code] {
+			continue
+		}
+
+		hash, err := meta.Hash.Sum(h.rnd, []byte(code))
+		if err != nil {
+			return nil, err
+		}
+
+		seen[code] = true
+		codes = append(codes, mfaRecoveryCode{
+			Code: code,
+			Hash: string(hash),
+		})
+	}
+
+	result := &mfaRecoverySet{
+		format: format,
+		codes:  codes,
+	}
+
+	return result, nil
+}
+
+func generateRecoveryCode(rnd *rand.Rand, groups, size int, separator string, pool []byte) string {
+	parts := make([]string, groups)
+	for i := range parts {
+		parts[i] = string(generatePassword(rnd, size, pool))
+	}
+
+	return strings.Join(parts, separator)
+}
+
+func mfaRecoveryFormat(meta *MFARecoveryCodesMeta) string {
+	return fmt.Sprintf("%d:%d:%d:%q:%q:%s", meta.Count, meta.Groups, meta.GroupSize, meta.Separator, meta.Alphabet, meta.Hash)
+}
@@ -0,0 +1,405 @@
+package fake_test
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/assert"
+	"github.com/UiP9AV6Y/fake-secrets/internal/handlers/fake"
+)
+
+type recoveryCode struct {
+	Code string `json:"code"`
+	Hash string `json:"hash"`
+	Used bool   `json:"used"`
+}
+
+func TestMFAHandlerServeRecoveryCodes(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", assertRecoveryCodes(
+						assertRecoveryCodeCount(10),
+						assertRecoveryCodeFormat(`^[a-z0-9]{4}-[a-z0-9]{4}$`),
+						assertRecoveryCodeHashes(),
+					)),
+				),
+			},
+		},
+		"grouping": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "3"),
+				WithRequestQuery("groups", "3"),
+				WithRequestQuery("group_size", "5"),
+				WithRequestQuery("separator", " "),
+				WithRequestQuery("upper", "true"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", assertRecoveryCodes(
+						assertRecoveryCodeCount(3),
+						assertRecoveryCodeFormat(`^[A-Z]{5} [A-Z]{5} [A-Z]{5}$`),
+						assertRecoveryCodeHashes(),
+					)),
+				),
+			},
+		},
+		"alphabet": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "4"),
+				WithRequestQuery("groups", "1"),
+				WithRequestQuery("group_size", "12"),
+				WithRequestQuery("alphabet", "0123456789ABCDEF"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", assertRecoveryCodes(
+						assertRecoveryCodeCount(4),
+						assertRecoveryCodeFormat(`^[0-9A-F]{12}$`),
+						assertRecoveryCodeHashes(),
+					)),
+				),
+			},
+		},
+		"argon2": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "2"),
+				WithRequestQuery("hash", "argon2"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", assertRecoveryCodes(
+						assertRecoveryCodeCount(2),
+						assertRecoveryCodeFormat(`^[a-z0-9]{4}-[a-z0-9]{4}$`),
+						assertRecoveryCodeHashes(),
+					)),
+				),
+			},
+		},
+		"repeated_alphabet": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "2"),
+				WithRequestQuery("groups", "1"),
+				WithRequestQuery("group_size", "1"),
+				WithRequestQuery("alphabet", "aabbaa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", assertRecoveryCodes(
+						assertRecoveryCodeCount(2),
+						assertRecoveryCodeFormat(`^[ab]$`),
+						assertRecoveryCodeHashes(),
+					)),
+				),
+			},
+		},
+		"invalid_count": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "ten"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"invalid_hash": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("hash", "md5"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"excessive_count": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "100000"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"excessive_length": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("groups", "4611686018427387904"),
+				WithRequestQuery("group_size", "4"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"exhausted_repeated_alphabet": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "2"),
+				WithRequestQuery("groups", "1"),
+				WithRequestQuery("group_size", "1"),
+				WithRequestQuery("alphabet", "aa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"non_ascii_alphabet": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("alphabet", "äöü"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"exhausted_alphabet": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("count", "5"),
+				WithRequestQuery("groups", "1"),
+				WithRequestQuery("group_size", "2"),
+				WithRequestQuery("alphabet", "ab"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := rand.New(rand.NewSource(0))
+			subject := fake.NewMFAHandler(rnd, logger)
+			reqopt := append([]requestOption{
+				WithRequestPath("mfa"),
+				WithRequestPathValue("account", name),
+				WithRequestPath("recovery-codes"),
+			}, test.HaveRequest...)
+			w := httptest.NewRecorder()
+
+			subject.ServeRecoveryCodes(w, newRequest(t.Context(), reqopt...))
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestMFAHandlerServeRedeem(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	rnd := rand.New(rand.NewSource(0))
+	subject := fake.NewMFAHandler(rnd, logger)
+	issue := func(form ...string) *http.Response {
+		reqopt := []requestOption{
+			WithRequestPath("mfa"),
+			WithRequestPathValue("account", "redeem"),
+			WithRequestPath("recovery-codes"),
+		}
+
+		for i := 0; i+1 < len(form); i += 2 {
+			reqopt = append(reqopt, WithRequestQuery(form[i], form[i+1]))
+		}
+
+		w := httptest.NewRecorder()
+		subject.ServeRecoveryCodes(w, newRequest(t.Context(), reqopt...))
+
+		return w.Result()
+	}
+	redeem := func(account, code string) *http.Response {
+		reqopt := []requestOption{
+			WithRequestMethod(http.MethodPost),
+			WithRequestPath("mfa"),
+			WithRequestPathValue("account", account),
+			WithRequestPath("recovery-codes"),
+			WithRequestPath("redeem"),
+		}
+
+		if code != "" {
+			reqopt = append(reqopt, WithRequestForm("code", code))
+		}
+
+		w := httptest.NewRecorder()
+		subject.ServeRedeem(w, newRequest(t.Context(), reqopt...))
+
+		return w.Result()
+	}
+	issued := func(assertions ...assert.Assertion[[]recoveryCode]) assert.Assertions[*http.Response] {
+		return assert.Assertions[*http.Response]{
+			assert.HTTPResponseStatusCode(http.StatusOK),
+			assert.HTTPResponseBodyJSON(
+				assertDTOString("secret", assertRecoveryCodes(assertions...)),
+			),
+		}
+	}
+	redeemed := func(valid bool, remaining float64) assert.Assertions[*http.Response] {
+		return assert.Assertions[*http.Response]{
+			assert.HTTPResponseStatusCode(http.StatusOK),
+			assert.HTTPResponseBodyJSON(
+				assertDTOBool("valid", valid),
+				assertDTONumber("remaining", remaining),
+			),
+		}
+	}
+
+	assert.Assert(t, redeem("redeem", "abcd-efgh"), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusNotFound),
+	})
+
+	codes := decodeRecoveryCodes(t, issue("count", "3", "hash", "argon2"))
+	assert.Assert(t, redeem("redeem", codes[1].Code), redeemed(true, 2))
+	assert.Assert(t, redeem("redeem", codes[1].Code), redeemed(false, 2))
+	assert.Assert(t, redeem("redeem", "not-issued"), redeemed(false, 2))
+	assert.Assert(t, redeem("redeem", ""), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+
+	assert.Assert(t, issue("count", "3", "hash", "argon2"), issued(
+		assertRecoveryCode(0, codes[0].Code, false),
+		assertRecoveryCode(1, codes[1].Code, true),
+		assertRecoveryCode(2, codes[2].Code, false),
+	))
+
+	assert.Assert(t, redeem("redeem", codes[0].Code), redeemed(true, 1))
+
+	regenerated := decodeRecoveryCodes(t, issue("count", "3", "hash", "argon2", "regenerate", "true"))
+
+	assert.Assert(t, redeem("redeem", codes[2].Code), redeemed(false, 3))
+	assert.Assert(t, redeem("redeem", regenerated[2].Code), redeemed(true, 2))
+}
+
+func assertRecoveryCodes(assertions ...assert.Assertion[[]recoveryCode]) assert.Assertion[string] {
+	return func(t *testing.T, secret string) {
+		t.Helper()
+
+		var codes []recoveryCode
+		if err := json.Unmarshal([]byte(secret), &codes); err != nil {
+			t.Fatalf("malformed recovery codes %q: %v", secret, err)
+		}
+
+		for _, a := range assertions {
+			a(t, codes)
+		}
+	}
+}
+
+func assertRecoveryCodeCount(want int) assert.Assertion[[]recoveryCode] {
+	return func(t *testing.T, codes []recoveryCode) {
+		t.Helper()
+
+		if len(codes) != want {
+			t.Errorf("recovery codes: got %d, want %d", len(codes), want)
+		}
+	}
+}
+
+func assertRecoveryCodeFormat(pattern string) assert.Assertion[[]recoveryCode] {
+	return func(t *testing.T, codes []recoveryCode) {
+		t.Helper()
+
+		re := regexp.MustCompile(pattern)
+		seen := map[string]bool{}
+		for _, code := range codes {
+			if !re.MatchString(code.Code) {
+				t.Errorf("recovery code %q does not match %s", code.Code, pattern)
+			}
+
+			if seen[code.Code] {
+				t.Errorf("recovery code %q issued more than once", code.Code)
+			}
+
+			seen[code.Code] = true
+		}
+	}
+}
+
+func assertRecoveryCodeHashes() assert.Assertion[[]recoveryCode] {
+	return func(t *testing.T, codes []recoveryCode) {
+		t.Helper()
+
+		for _, code := range codes {
+			if err := compareRecoveryCodeHash(code.Hash, code.Code); err != nil {
+				t.Errorf("recovery code %q hash: %v", code.Code, err)
+			}
+		}
+	}
+}
+
+func assertRecoveryCode(index int, code string, used bool) assert.Assertion[[]recoveryCode] {
+	return func(t *testing.T, codes []recoveryCode) {
+		t.Helper()
+
+		if index >= len(codes) {
+			t.Fatalf("recovery code %d: got %d codes", index, len(codes))
+		}
+
+		if codes[index].Code != code || codes[index].Used != used {
+			t.Errorf("recovery code %d: got %+v, want %q used=%t", index, codes[index], code, used)
+		}
+	}
+}
+
+func decodeRecoveryCodes(t *testing.T, res *http.Response) []recoveryCode {
+	t.Helper()
+
+	var codes []recoveryCode
+	secret := decodeSecret(t, res)
+	if err := json.Unmarshal([]byte(secret), &codes); err != nil {
+		t.Fatalf("malformed recovery codes %q: %v", secret, err)
+	}
+
+	return codes
+}
+
+func compareRecoveryCodeHash(hash, code string) error {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(code))
+	}
+
+	var version int
+	var memory, passes uint32
+	var parallelism uint8
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return fmt.Errorf("malformed argon2id hash %q", hash)
+	}
+
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &passes, &parallelism); err != nil {
+		return err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return err
+	}
+
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return err
+	}
+
+	got := argon2.IDKey([]byte(code), salt, passes, memory, parallelism, uint32(len(want)))
+	if version != argon2.Version || subtle.ConstantTimeCompare(got, want) != 1 {
+		return fmt.Errorf("argon2id hash %q does not match", hash)
+	}
+
+	return nil
+}
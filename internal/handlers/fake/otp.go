@@ -0,0 +1,126 @@
+package fake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	nethttp "net/http"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+	"github.com/pquerna/otp"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+const (
+	ContentTypePNG = "image/png"
+	ContentTypeSVG = "image/svg+xml"
+)
+
+var (
+	ErrNoOTPCode        = errors.New("code is required")
+	ErrNoOTPResyncCodes = errors.New("code and next_code are required")
+
+	MaxTOTPSkew   uint64 = 10
+	MaxHOTPWindow uint64 = 1000
+	MaxQRCodeSize int64  = 4096
+)
+
+type otpVerification struct {
+	Valid   bool   `json:"valid"`
+	Counter uint64 `json:"counter,omitempty"`
+	Drift   int64  `json:"drift"`
+}
+
+func serveOTPVerification(w nethttp.ResponseWriter, data *otpVerification, err error) {
+	if errors.Is(err, otp.ErrValidateInputInvalidLength) {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	http.ServeJSON(w, data)
+}
+
+func serveOTPQRCode(w nethttp.ResponseWriter, key *otp.Key, image *QRCodeMeta, meta any) {
+	var buf bytes.Buffer
+	var contentType string
+
+	switch image.Format {
+	case "svg":
+		code, err := qr.Encode(key.String(), qr.M, qr.Auto)
+		if err == nil && code.Bounds().Dx() > image.Size {
+			err = fmt.Errorf("can not scale QR code to an image smaller than %dx%d", code.Bounds().Dx(), code.Bounds().Dy())
+		}
+
+		if err != nil {
+			http.ServeError(w, nethttp.StatusBadRequest, err)
+			return
+		}
+
+		contentType = ContentTypeSVG
+		encodeSVG(&buf, code, image.Size)
+	default:
+		img, err := key.Image(image.Size, image.Size)
+		if err != nil {
+			http.ServeError(w, nethttp.StatusBadRequest, err)
+			return
+		}
+
+		contentType = ContentTypePNG
+		if err := png.Encode(&buf, img); err != nil {
+			http.ServeError(w, nethttp.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if image.Encoding == "base64" {
+		data := []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+
+		http.ServeSecret(w, data, meta)
+	} else {
+		http.ServeBlob(w, contentType, buf.Bytes())
+	}
+}
+
+// one unit per QR module, scaling is left to the SVG viewer
+func encodeSVG(w io.Writer, code barcode.Barcode, size int) {
+	bounds := code.Bounds()
+
+	_, _ = fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n",
+		size, size, bounds.Dx(), bounds.Dy())
+	_, _ = fmt.Fprintf(w, `<rect width="%d" height="%d" fill="#fff"/>`+"\n", bounds.Dx(), bounds.Dy())
+	_, _ = io.WriteString(w, `<path fill="#000" d="`)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; {
+			if !isDarkPixel(code.At(x, y)) {
+				x++
+				continue
+			}
+
+			run := x
+			for run < bounds.Max.X && isDarkPixel(code.At(run, y)) {
+				run++
+			}
+
+			_, _ = fmt.Fprintf(w, "M%d %dh%dv1h-%dz", x-bounds.Min.X, y-bounds.Min.Y, run-x, run-x)
+			x = run
+		}
+	}
+
+	_, _ = io.WriteString(w, "\"/>\n</svg>\n")
+}
+
+func isDarkPixel(c color.Color) bool {
+	gray := color.GrayModel.Convert(c).(color.Gray)
+
+	return gray.Y < 0x80
+}
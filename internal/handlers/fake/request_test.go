@@ -1,7 +1,9 @@
 package fake_test
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -41,12 +43,36 @@ func WithRequestQuery(k, v string) requestOption {
 	}
 }
 
+func WithRequestMethod(m string) requestOption {
+	return func(rb *requestBuilder) {
+		rb.method = m
+	}
+}
+
+func WithRequestBody(contentType string, body []byte) requestOption {
+	return func(rb *requestBuilder) {
+		rb.method = http.MethodPost
+		rb.body = body
+		rb.header.Set("Content-Type", contentType)
+	}
+}
+
+func WithRequestForm(k, v string) requestOption {
+	return func(rb *requestBuilder) {
+		rb.method = http.MethodPost
+		rb.form.Add(k, v)
+		rb.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+}
+
 type requestBuilder struct {
 	hostPost   string
 	scheme     string
 	method     string
 	header     http.Header
 	query      url.Values
+	form       url.Values
+	body       []byte
 	path       []string
 	pathValues map[string]string
 }
@@ -58,6 +84,7 @@ func newRequestBuilder() *requestBuilder {
 		method:     http.MethodGet,
 		header:     http.Header{},
 		query:      url.Values{},
+		form:       url.Values{},
 		path:       []string{},
 		pathValues: map[string]string{},
 	}
@@ -88,6 +115,18 @@ func (rb *requestBuilder) DecorateRequest(req *http.Request) *http.Request {
 	return req
 }
 
+func (rb *requestBuilder) Body() io.Reader {
+	if len(rb.form) > 0 {
+		return strings.NewReader(rb.form.Encode())
+	}
+
+	if rb.body != nil {
+		return bytes.NewReader(rb.body)
+	}
+
+	return nil
+}
+
 func newRequest(ctx context.Context, opts ...requestOption) *http.Request {
 	builder := newRequestBuilder()
 
@@ -96,6 +135,6 @@ func newRequest(ctx context.Context, opts ...requestOption) *http.Request {
 	}
 
 	return builder.DecorateRequest(
-		httptest.NewRequestWithContext(ctx, builder.method, builder.URL(), nil),
+		httptest.NewRequestWithContext(ctx, builder.method, builder.URL(), builder.Body()),
 	)
 }
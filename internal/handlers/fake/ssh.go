@@ -4,10 +4,10 @@ import (
 	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/rsa"
-	"encoding/pem"
 	"io"
 	"log/slog"
 	nethttp "net/http"
+	"time"
 
 	"golang.org/x/crypto/ssh"
 
@@ -18,22 +18,27 @@ import (
 
 type SSHHandler struct {
 	logger  *slog.Logger
+	start   time.Time
 	rand    io.Reader
 	rsa     cache.Cacher[*rsa.PrivateKey]
 	ecdsa   cache.Cacher[*ecdsa.PrivateKey]
 	ed25519 cache.Cacher[ed25519.PrivateKey]
+	ca      *sshAuthority
 }
 
-func NewSSHHandler(rnd io.Reader, logger *slog.Logger) *SSHHandler {
+func NewSSHHandler(start time.Time, rnd io.Reader, logger *slog.Logger) *SSHHandler {
 	rsa := cache.NewCacher[*rsa.PrivateKey]()
 	ecdsa := cache.NewCacher[*ecdsa.PrivateKey]()
 	ed25519 := cache.NewCacher[ed25519.PrivateKey]()
+	ca := newSSHAuthority(rnd)
 	result := &SSHHandler{
 		logger:  logger,
+		start:   start,
 		rand:    rnd,
 		rsa:     rsa,
 		ecdsa:   ecdsa,
 		ed25519: ed25519,
+		ca:      ca,
 	}
 
 	return result
@@ -57,7 +62,7 @@ func (h *SSHHandler) RouteCertificate(cfg *config.Config) (string, nethttp.Handl
 
 func (h *SSHHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Request) {
 	name := r.PathValue("hostname")
-	meta, err := ParseSSHMeta(name, r)
+	meta, err := ParseSSHCertificateMeta(name, h.start, r)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
@@ -71,7 +76,19 @@ func (h *SSHHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 		return
 	}
 
-	cert, err := ssh.NewPublicKey(key)
+	template := &ssh.Certificate{
+		CertType:        uint32(meta.CertType),
+		KeyId:           meta.Subject,
+		ValidPrincipals: meta.Principals,
+		ValidAfter:      meta.ValidAfter(),
+		ValidBefore:     meta.ValidBefore(),
+		Permissions: ssh.Permissions{
+			CriticalOptions: meta.CriticalOptions,
+			Extensions:      meta.Extensions,
+		},
+	}
+
+	cert, err := h.ca.Sign(meta.AuthorityCrypto, template, key)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
@@ -82,13 +99,37 @@ func (h *SSHHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 	http.ServeSecret(w, data, meta)
 }
 
+func (h *SSHHandler) RouteAuthorityPublicKey(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("ssh", AuthorityHostname, "keys"), h.ServeAuthorityPublicKey
+}
+
+func (h *SSHHandler) ServeAuthorityPublicKey(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseSSHAuthorityMeta(r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving SSH authority public key", "meta", meta)
+
+	key, err := h.ca.PublicKey(&meta.CryptoMeta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data := ssh.MarshalAuthorizedKey(key)
+
+	http.ServeSecret(w, data, meta)
+}
+
 func (h *SSHHandler) RoutePrivateKey(cfg *config.Config) (string, nethttp.HandlerFunc) {
 	return cfg.HandlerPattern("ssh", "{hostname}", "keys"), h.ServePrivateKey
 }
 
 func (h *SSHHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Request) {
 	name := r.PathValue("hostname")
-	meta, err := ParseSSHMeta(name, r)
+	meta, err := ParseSSHKeyMeta(name, r)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
@@ -102,13 +143,11 @@ func (h *SSHHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Reques
 		return
 	}
 
-	block, err := ssh.MarshalPrivateKey(key, "")
+	data, err := encodePrivateKey(h.rand, key, meta.Format, meta.Passphrase, meta.Subject)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
-	data := pem.EncodeToMemory(block)
-
 	http.ServeSecret(w, data, meta)
 }
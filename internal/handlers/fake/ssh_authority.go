@@ -0,0 +1,73 @@
+package fake
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
+)
+
+type sshAuthority struct {
+	rand    io.Reader
+	rsa     cache.Cacher[*rsa.PrivateKey]
+	ecdsa   cache.Cacher[*ecdsa.PrivateKey]
+	ed25519 cache.Cacher[ed25519.PrivateKey]
+	cert    cache.Cacher[*ssh.Certificate]
+}
+
+func newSSHAuthority(rnd io.Reader) *sshAuthority {
+	rsa := cache.NewCacher[*rsa.PrivateKey]()
+	ecdsa := cache.NewCacher[*ecdsa.PrivateKey]()
+	ed25519 := cache.NewCacher[ed25519.PrivateKey]()
+	cert := cache.NewCacher[*ssh.Certificate]()
+	result := &sshAuthority{
+		rand:    rnd,
+		rsa:     rsa,
+		ecdsa:   ecdsa,
+		ed25519: ed25519,
+		cert:    cert,
+	}
+
+	return result
+}
+
+func (a *sshAuthority) RSACache() cache.Cacher[*rsa.PrivateKey] {
+	return a.rsa
+}
+
+func (a *sshAuthority) ECDSACache() cache.Cacher[*ecdsa.PrivateKey] {
+	return a.ecdsa
+}
+
+func (a *sshAuthority) ED25519Cache() cache.Cacher[ed25519.PrivateKey] {
+	return a.ed25519
+}
+
+func (a *sshAuthority) PublicKey(meta *CryptoMeta) (ssh.PublicKey, error) {
+	pub, _, err := LoadHandlerKey(a, meta, a.rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewPublicKey(pub)
+}
+
+func (a *sshAuthority) Sign(meta *CryptoMeta, template *ssh.Certificate, key any) (*ssh.Certificate, error) {
+	_, signer, err := LoadHandlerKey(a, meta, a.rand)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &cache.SSHCertLoader{
+		Template: template,
+		Key:      key,
+		Signer:   signer,
+		Random:   a.rand,
+	}
+
+	return a.cert.Load(req)
+}
@@ -0,0 +1,160 @@
+package fake
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+func (h *SSHHandler) RouteKnownHosts(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("ssh", "{hostname}", "known_hosts"), h.ServeKnownHosts
+}
+
+func (h *SSHHandler) ServeKnownHosts(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseSSHKnownHostsMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated SSH known_hosts entry", "meta", meta)
+
+	key, err := h.loadPublicKey(&meta.SSHMeta, meta.AuthorityCrypto)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	port := strconv.FormatInt(meta.Port, 10)
+	hosts := make([]string, 0, len(meta.Addresses))
+	for _, a := range meta.Addresses {
+		hosts = append(hosts, knownhosts.Normalize(net.JoinHostPort(a, port)))
+	}
+
+	if !meta.Hashed {
+		hosts = []string{strings.Join(hosts, ",")}
+	}
+
+	marker := ""
+	if meta.CertAuthority {
+		marker = "@cert-authority "
+	}
+
+	lines := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if meta.Hashed {
+			host, err = hashHostname(host, h.rand)
+			if err != nil {
+				http.ServeError(w, nethttp.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		lines = append(lines, marker+host+" "+serializePublicKey(key))
+	}
+
+	data := []byte(strings.Join(lines, "\n"))
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *SSHHandler) RouteAuthorizedKeys(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("ssh", "{hostname}", "authorized_keys"), h.ServeAuthorizedKeys
+}
+
+func (h *SSHHandler) ServeAuthorizedKeys(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseSSHAuthorizedKeysMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated SSH authorized_keys entry", "meta", meta)
+
+	key, err := h.loadPublicKey(&meta.SSHMeta, meta.AuthorityCrypto)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	options := make([]string, 0, len(meta.Options)+len(meta.Environment)+4)
+	if meta.CertAuthority {
+		options = append(options, "cert-authority")
+	}
+
+	options = append(options, meta.Options...)
+
+	if meta.Command != "" {
+		options = append(options, "command="+quoteOption(meta.Command))
+	}
+
+	if len(meta.From) > 0 {
+		options = append(options, "from="+quoteOption(strings.Join(meta.From, ",")))
+	}
+
+	if len(meta.Principals) > 0 {
+		options = append(options, "principals="+quoteOption(strings.Join(meta.Principals, ",")))
+	}
+
+	for _, e := range meta.Environment {
+		options = append(options, "environment="+quoteOption(e))
+	}
+
+	line := serializePublicKey(key) + " " + name
+	if len(options) > 0 {
+		line = strings.Join(options, ",") + " " + line
+	}
+
+	data := []byte(line)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *SSHHandler) loadPublicKey(meta *SSHMeta, authority *CryptoMeta) (ssh.PublicKey, error) {
+	if authority != nil {
+		return h.ca.PublicKey(authority)
+	}
+
+	pub, _, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewPublicKey(pub)
+}
+
+func serializePublicKey(key ssh.PublicKey) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key)))
+}
+
+func quoteOption(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func hashHostname(host string, rnd io.Reader) (string, error) {
+	salt := make([]byte, sha1.Size)
+	if _, err := io.ReadFull(rnd, salt); err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha1.New, salt)
+	_, _ = mac.Write([]byte(host))
+
+	result := "|1|" + base64.StdEncoding.EncodeToString(salt) +
+		"|" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return result, nil
+}
@@ -6,10 +6,15 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	nethttp "net/http"
+	"net/mail"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
@@ -18,6 +23,12 @@ import (
 	"github.com/UiP9AV6Y/fake-secrets/internal/http"
 )
 
+var (
+	ErrNoCertificateRequest = errors.New("no certificate signing request provided")
+
+	MaxCertificateRequestSize int64 = 64 * 1024
+)
+
 type TLSHandler struct {
 	logger  *slog.Logger
 	start   time.Time
@@ -26,6 +37,7 @@ type TLSHandler struct {
 	ecdsa   cache.Cacher[*ecdsa.PrivateKey]
 	ed25519 cache.Cacher[ed25519.PrivateKey]
 	cert    cache.Cacher[crypto.Certificate]
+	ca      *tlsAuthority
 }
 
 func NewTLSHandler(start time.Time, rnd io.Reader, logger *slog.Logger) *TLSHandler {
@@ -33,6 +45,7 @@ func NewTLSHandler(start time.Time, rnd io.Reader, logger *slog.Logger) *TLSHand
 	ecdsa := cache.NewCacher[*ecdsa.PrivateKey]()
 	ed25519 := cache.NewCacher[ed25519.PrivateKey]()
 	cert := cache.NewCacher[crypto.Certificate]()
+	ca := newTLSAuthority(start, rnd)
 	result := &TLSHandler{
 		logger:  logger,
 		start:   start,
@@ -41,6 +54,7 @@ func NewTLSHandler(start time.Time, rnd io.Reader, logger *slog.Logger) *TLSHand
 		ecdsa:   ecdsa,
 		ed25519: ed25519,
 		cert:    cert,
+		ca:      ca,
 	}
 
 	return result
@@ -72,17 +86,156 @@ func (h *TLSHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 
 	h.logger.Debug("serving generated TLS certificate", "meta", meta)
 
-	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	base := http.ParseHeaderRouteURL(r, 2)
+	der, _, err := h.loadCertificate(meta, base)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data := encodeCertificates(der)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) RouteChain(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", "{hostname}", "chains"), h.ServeChain
+}
+
+func (h *TLSHandler) ServeChain(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseTLSMeta(name, h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated TLS certificate chain", "meta", meta)
+
+	base := http.ParseHeaderRouteURL(r, 2)
+	der, chain, err := h.loadCertificate(meta, base)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	certs := [][]byte{der}
+	for _, c := range chain {
+		certs = append(certs, c.Raw)
+	}
+
+	data := encodeCertificates(certs...)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) RouteBundle(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", "bundle"), h.ServeBundle
+}
+
+func (h *TLSHandler) ServeBundle(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta := NewStaticMeta(r)
+
+	h.logger.Debug("serving TLS authority trust bundle", "meta", meta)
+
+	roots := h.ca.Roots()
+	certs := make([][]byte, 0, len(roots))
+	for _, c := range roots {
+		certs = append(certs, c.Raw)
+	}
+
+	data := encodeCertificates(certs...)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) RouteSign(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("tls", "sign"), h.ServeSign
+}
+
+func (h *TLSHandler) ServeSign(w nethttp.ResponseWriter, r *nethttp.Request) {
+	csr, err := parseCertificateRequest(r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	meta, err := ParseTLSSigningMeta(csr.Subject.CommonName, h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving signed TLS certificate", "meta", meta)
+
+	chain, signer, err := h.ca.Load(&meta.TLSMeta)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
+	template := &x509.Certificate{
+		Subject:               csr.Subject,
+		NotBefore:             meta.NotBefore(),
+		NotAfter:              meta.NotAfter(),
+		ExtKeyUsage:           meta.Usage.ExtKeyUsage(),
+		BasicConstraintsValid: true,
+		DNSNames:              csr.DNSNames,
+		EmailAddresses:        csr.EmailAddresses,
+		IPAddresses:           csr.IPAddresses,
+		URIs:                  csr.URIs,
+	}
+
+	if csr.PublicKeyAlgorithm == x509.RSA {
+		template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+	}
+
+	base := http.ParseHeaderRouteURL(r, 1)
+	addRevocationEndpoints(template, &meta.TLSMeta, base)
+
+	req := &cache.CertLoader{
+		Parent:   chain[0],
+		Template: template,
+		Key:      csr.PublicKey,
+		Signer:   signer,
+		Random:   h.rand,
+	}
+	der, err := h.cert.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := h.ca.Issue(chain[0], der); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	certs := [][]byte{der}
+	if meta.Chain {
+		for _, c := range chain {
+			certs = append(certs, c.Raw)
+		}
+	}
+
+	data := encodeCertificates(certs...)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) loadCertificate(meta *TLSMeta, base string) (der crypto.Certificate, chain []*x509.Certificate, err error) {
+	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	template := &x509.Certificate{
 		Subject:               meta.Subject(),
 		NotBefore:             meta.NotBefore(),
 		NotAfter:              meta.NotAfter(),
-		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		ExtKeyUsage:           meta.Usage.ExtKeyUsage(),
 		BasicConstraintsValid: true,
 	}
 
@@ -92,12 +245,8 @@ func (h *TLSHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 		template.KeyUsage = x509.KeyUsageDigitalSignature
 	}
 
-	for _, h := range meta.SubjectAltNames() {
-		if ip := net.ParseIP(h); ip != nil {
-			template.IPAddresses = append(template.IPAddresses, ip)
-		} else {
-			template.DNSNames = append(template.DNSNames, h)
-		}
+	for _, n := range meta.SubjectAltNames() {
+		addSubjectAltName(template, n)
 	}
 
 	req := &cache.CertLoader{
@@ -105,30 +254,88 @@ func (h *TLSHandler) ServeCertificate(w nethttp.ResponseWriter, r *nethttp.Reque
 		Key:      key,
 		Random:   h.rand,
 	}
-	der, err := h.cert.Load(req)
+
+	if meta.Authority != crypto.AuthorityNone {
+		var signer any
+
+		chain, signer, err = h.ca.Load(meta)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req.Parent = chain[0]
+		req.Signer = signer
+
+		addRevocationEndpoints(template, meta, base)
+	}
+
+	der, err = h.cert.Load(req)
 	if err != nil {
-		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return nil, nil, err
+	}
+
+	if req.Parent != nil {
+		if _, err := h.ca.Issue(req.Parent, der); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return der, chain, nil
+}
+
+func (h *TLSHandler) RouteAuthorityCertificate(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", AuthorityHostname, "certificates"), h.ServeAuthorityCertificate
+}
+
+func (h *TLSHandler) ServeAuthorityCertificate(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseTLSAuthorityMeta(h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
 	}
 
-	block := &pem.Block{
-		Type:    "CERTIFICATE",
-		Headers: nil,
-		Bytes:   der,
+	h.logger.Debug("serving generated TLS authority certificate", "meta", meta)
+
+	chain, _, err := h.ca.Load(meta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
 	}
 
-	data := pem.EncodeToMemory(block)
+	data := encodeCertificates(chain[0].Raw)
 
 	http.ServeSecret(w, data, meta)
 }
 
+func (h *TLSHandler) RouteAuthorityPrivateKey(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", AuthorityHostname, "keys"), h.ServeAuthorityPrivateKey
+}
+
+func (h *TLSHandler) ServeAuthorityPrivateKey(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseTLSAuthorityKeyMeta(h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated TLS authority private key", "meta", meta)
+
+	_, key, err := h.ca.Load(&meta.TLSMeta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	h.servePrivateKey(w, key, meta)
+}
+
 func (h *TLSHandler) RoutePrivateKey(cfg *config.Config) (string, nethttp.HandlerFunc) {
 	return cfg.HandlerPattern("tls", "{hostname}", "keys"), h.ServePrivateKey
 }
 
 func (h *TLSHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Request) {
 	name := r.PathValue("hostname")
-	meta, err := ParseTLSMeta(name, h.start, r)
+	meta, err := ParseTLSKeyMeta(name, h.start, r)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusBadRequest, err)
 		return
@@ -142,19 +349,99 @@ func (h *TLSHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Reques
 		return
 	}
 
-	der, err := x509.MarshalPKCS8PrivateKey(key)
+	h.servePrivateKey(w, key, meta)
+}
+
+func (h *TLSHandler) servePrivateKey(w nethttp.ResponseWriter, key any, meta *TLSKeyMeta) {
+	data, err := encodePrivateKey(h.rand, key, meta.Format, meta.Passphrase, meta.CryptoMeta.Subject)
 	if err != nil {
 		http.ServeError(w, nethttp.StatusInternalServerError, err)
 		return
 	}
 
+	http.ServeSecret(w, data, meta)
+}
+
+func addSubjectAltName(template *x509.Certificate, name string) {
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if u, err := url.Parse(name); err == nil && isSubjectAltURI(u) {
+		template.URIs = append(template.URIs, u)
+	} else if a, err := mail.ParseAddress(name); err == nil && a.Address == name {
+		template.EmailAddresses = append(template.EmailAddresses, a.Address)
+	} else {
+		template.DNSNames = append(template.DNSNames, name)
+	}
+}
+
+func isSubjectAltURI(u *url.URL) bool {
+	if u.Scheme == "" {
+		return false
+	}
+
+	return u.Host != "" || strings.EqualFold(u.Scheme, "urn")
+}
+
+func encodeCertificates(certs ...[]byte) []byte {
+	var data []byte
+
+	for _, der := range certs {
+		block := &pem.Block{
+			Type:    "CERTIFICATE",
+			Headers: nil,
+			Bytes:   der,
+		}
+
+		data = append(data, pem.EncodeToMemory(block)...)
+	}
+
+	return data
+}
+
+func parseCertificateRequest(r *nethttp.Request) (*x509.CertificateRequest, error) {
+	var data []byte
+
+	if http.IsFormRequest(r) {
+		data = []byte(r.FormValue("csr"))
+	} else {
+		body, err := io.ReadAll(io.LimitReader(r.Body, MaxCertificateRequestSize))
+		if err != nil {
+			return nil, err
+		}
+
+		data = body
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+
+		data = block.Bytes
+	}
+
+	if len(data) == 0 {
+		return nil, ErrNoCertificateRequest
+	}
+
+	csr, err := x509.ParseCertificateRequest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+
+	return csr, nil
+}
+
+func encodeRevocationList(der []byte) []byte {
 	block := &pem.Block{
-		Type:    "PRIVATE KEY",
+		Type:    "X509 CRL",
 		Headers: nil,
 		Bytes:   der,
 	}
 
-	data := pem.EncodeToMemory(block)
-
-	http.ServeSecret(w, data, meta)
+	return pem.EncodeToMemory(block)
 }
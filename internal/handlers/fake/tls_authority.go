@@ -0,0 +1,299 @@
+package fake
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/cache"
+	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
+)
+
+var (
+	ErrNoAuthority      = errors.New("no certificate authority requested")
+	ErrUnknownAuthority = errors.New("certificate authority has not been issued")
+
+	TLSAuthorityValidity  = 10 * 365 * 24 * time.Hour
+	TLSRevocationValidity = 24 * time.Hour
+)
+
+type tlsAuthority struct {
+	start   time.Time
+	rand    io.Reader
+	rsa     cache.Cacher[*rsa.PrivateKey]
+	ecdsa   cache.Cacher[*ecdsa.PrivateKey]
+	ed25519 cache.Cacher[ed25519.PrivateKey]
+	cert    cache.Cacher[crypto.Certificate]
+	roots   cache.Store[[sha256.Size]byte, *x509.Certificate]
+	issuers cache.Store[[sha256.Size]byte, *tlsIssuer]
+}
+
+type tlsIssuer struct {
+	cert    *x509.Certificate
+	key     any
+	issued  cache.Store[string, *x509.Certificate]
+	revoked cache.Store[string, x509.RevocationListEntry]
+}
+
+func newTLSAuthority(start time.Time, rnd io.Reader) *tlsAuthority {
+	rsa := cache.NewCacher[*rsa.PrivateKey]()
+	ecdsa := cache.NewCacher[*ecdsa.PrivateKey]()
+	ed25519 := cache.NewCacher[ed25519.PrivateKey]()
+	cert := cache.NewCacher[crypto.Certificate]()
+	roots := cache.NewStore[[sha256.Size]byte, *x509.Certificate]()
+	issuers := cache.NewStore[[sha256.Size]byte, *tlsIssuer]()
+	result := &tlsAuthority{
+		start:   start,
+		rand:    rnd,
+		rsa:     rsa,
+		ecdsa:   ecdsa,
+		ed25519: ed25519,
+		cert:    cert,
+		roots:   roots,
+		issuers: issuers,
+	}
+
+	return result
+}
+
+func (a *tlsAuthority) RSACache() cache.Cacher[*rsa.PrivateKey] {
+	return a.rsa
+}
+
+func (a *tlsAuthority) ECDSACache() cache.Cacher[*ecdsa.PrivateKey] {
+	return a.ecdsa
+}
+
+func (a *tlsAuthority) ED25519Cache() cache.Cacher[ed25519.PrivateKey] {
+	return a.ed25519
+}
+
+func (a *tlsAuthority) Load(meta *TLSMeta) (chain []*x509.Certificate, key any, err error) {
+	if meta.Authority == crypto.AuthorityNone || meta.AuthorityCrypto == nil {
+		return nil, nil, ErrNoAuthority
+	}
+
+	req := &cache.CertLoader{
+		Template: a.template(meta),
+		Random:   a.rand,
+	}
+
+	if parent := meta.Authority.Parent(); parent != crypto.AuthorityNone {
+		parentMeta := *meta
+		parentCrypto := *meta.AuthorityCrypto
+		parentCrypto.Subject = parent.String()
+		parentMeta.Authority = parent
+		parentMeta.AuthorityCrypto = &parentCrypto
+
+		chain, req.Signer, err = a.Load(&parentMeta)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		req.Parent = chain[0]
+	}
+
+	_, key, err = LoadHandlerKey(a, meta.AuthorityCrypto, a.rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Key = key
+	der, err := a.cert.Load(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a.register(cert, key)
+
+	if req.Parent == nil {
+		a.roots.Put(sha256.Sum256(cert.Raw), cert)
+	} else if _, err := a.Issue(req.Parent, der); err != nil {
+		return nil, nil, err
+	}
+
+	chain = append([]*x509.Certificate{cert}, chain...)
+
+	return chain, key, nil
+}
+
+func (a *tlsAuthority) Roots() []*x509.Certificate {
+	return a.roots.Values()
+}
+
+func (a *tlsAuthority) Issue(parent *x509.Certificate, der []byte) (*x509.Certificate, error) {
+	issuer, ok := a.issuers.Get(sha256.Sum256(parent.Raw))
+	if !ok {
+		return nil, ErrUnknownAuthority
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	issuer.issued.Put(cert.SerialNumber.String(), cert)
+
+	return cert, nil
+}
+
+func (a *tlsAuthority) Revoke(parent, cert *x509.Certificate, at time.Time, reason int) error {
+	issuer, ok := a.issuers.Get(sha256.Sum256(parent.Raw))
+	if !ok {
+		return ErrUnknownAuthority
+	}
+
+	entry := x509.RevocationListEntry{
+		SerialNumber:   cert.SerialNumber,
+		RevocationTime: at,
+		ReasonCode:     reason,
+	}
+
+	_, err := issuer.revoked.Update(cert.SerialNumber.String(), func(e x509.RevocationListEntry, ok bool) (x509.RevocationListEntry, error) {
+		if ok {
+			return e, nil
+		}
+
+		return entry, nil
+	})
+
+	return err
+}
+
+func (a *tlsAuthority) RevocationList(cert *x509.Certificate, now time.Time) ([]byte, error) {
+	issuer, ok := a.issuers.Get(sha256.Sum256(cert.Raw))
+	if !ok {
+		return nil, ErrUnknownAuthority
+	}
+
+	signer, ok := issuer.key.(stdcrypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unable to sign revocation list with key type %T", issuer.key)
+	}
+
+	entries := issuer.revoked.Values()
+	template := &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		Number:                    big.NewInt(int64(len(entries) + 1)),
+		ThisUpdate:                now,
+		NextUpdate:                now.Add(TLSRevocationValidity),
+	}
+
+	return x509.CreateRevocationList(a.rand, template, cert, signer)
+}
+
+func (a *tlsAuthority) OCSPResponse(req *ocsp.Request, now time.Time) ([]byte, error) {
+	issuer := a.lookup(req)
+	if issuer == nil {
+		return ocsp.UnauthorizedErrorResponse, nil
+	}
+
+	signer, ok := issuer.key.(stdcrypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unable to sign OCSP response with key type %T", issuer.key)
+	}
+
+	serial := req.SerialNumber.String()
+	template := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: req.SerialNumber,
+		IssuerHash:   req.HashAlgorithm,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(TLSRevocationValidity),
+	}
+
+	if e, ok := issuer.revoked.Get(serial); ok {
+		template.Status = ocsp.Revoked
+		template.RevokedAt = e.RevocationTime
+		template.RevocationReason = e.ReasonCode
+	} else if _, ok := issuer.issued.Get(serial); ok {
+		template.Status = ocsp.Good
+	}
+
+	return ocsp.CreateResponse(issuer.cert, issuer.cert, template, signer)
+}
+
+func (a *tlsAuthority) lookup(req *ocsp.Request) *tlsIssuer {
+	if !req.HashAlgorithm.Available() {
+		return nil
+	}
+
+	for _, i := range a.issuers.Values() {
+		var spki struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}
+
+		if _, err := asn1.Unmarshal(i.cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+			continue
+		}
+
+		h := req.HashAlgorithm.New()
+		_, _ = h.Write(spki.PublicKey.RightAlign())
+		if bytes.Equal(h.Sum(nil), req.IssuerKeyHash) {
+			return i
+		}
+	}
+
+	return nil
+}
+
+func (a *tlsAuthority) register(cert *x509.Certificate, key any) {
+	_, _ = a.issuers.Update(sha256.Sum256(cert.Raw), func(i *tlsIssuer, ok bool) (*tlsIssuer, error) {
+		if ok {
+			return i, nil
+		}
+
+		result := &tlsIssuer{
+			cert:    cert,
+			key:     key,
+			issued:  cache.NewStore[string, *x509.Certificate](),
+			revoked: cache.NewStore[string, x509.RevocationListEntry](),
+		}
+
+		return result, nil
+	})
+}
+
+func (a *tlsAuthority) template(meta *TLSMeta) *x509.Certificate {
+	name := "Root CA"
+	if meta.Authority == crypto.AuthorityIntermediate {
+		name = "Intermediate CA"
+	}
+
+	result := &x509.Certificate{
+		Subject: pkix.Name{
+			Organization: []string{meta.Organization},
+			CommonName:   meta.Organization + " " + name,
+		},
+		NotBefore:             a.start,
+		NotAfter:              a.start.Add(TLSAuthorityValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	if meta.Authority == crypto.AuthorityIntermediate {
+		result.MaxPathLenZero = true
+	}
+
+	return result
+}
@@ -0,0 +1,151 @@
+package fake
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+var ErrNoOCSPRequest = errors.New("no OCSP request provided")
+
+const (
+	ContentTypeCRL          = "application/pkix-crl"
+	ContentTypePEM          = "application/x-pem-file"
+	ContentTypeOCSPRequest  = "application/ocsp-request"
+	ContentTypeOCSPResponse = "application/ocsp-response"
+)
+
+func (h *TLSHandler) RouteRevoke(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("tls", "{hostname}", "revoke"), h.ServeRevoke
+}
+
+func (h *TLSHandler) ServeRevoke(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseTLSRevocationMeta(name, h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("revoking generated TLS certificate", "meta", meta)
+
+	base := http.ParseHeaderRouteURL(r, 2)
+	der, chain, err := h.loadCertificate(&meta.TLSMeta, base)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if err := h.ca.Revoke(chain[0], cert, meta.RevocationTime(), meta.Reason); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data := encodeCertificates(der)
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) RouteRevocationList(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", AuthorityHostname, "crl"), h.ServeRevocationList
+}
+
+func (h *TLSHandler) ServeRevocationList(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseTLSAuthorityMeta(h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	format := http.ParseFormString(r, "format", "der")
+	if format != "der" && format != "pem" {
+		http.ServeError(w, nethttp.StatusBadRequest, fmt.Errorf("invalid revocation list format %q", format))
+		return
+	}
+
+	h.logger.Debug("serving TLS certificate revocation list", "meta", meta)
+
+	chain, _, err := h.ca.Load(meta)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	der, err := h.ca.RevocationList(chain[0], time.Now())
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	if format == "pem" {
+		http.ServeBlob(w, ContentTypePEM, encodeRevocationList(der))
+	} else {
+		http.ServeBlob(w, ContentTypeCRL, der)
+	}
+}
+
+func (h *TLSHandler) RouteOCSP(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("tls", AuthorityHostname, "ocsp"), h.ServeOCSP
+}
+
+func (h *TLSHandler) RouteOCSPRequest(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodGet + " " + cfg.HandlerPattern("tls", AuthorityHostname, "ocsp", "{request...}"), h.ServeOCSP
+}
+
+func (h *TLSHandler) ServeOCSP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	data, err := parseOCSPRequest(r)
+	if err != nil {
+		http.ServeBlob(w, ContentTypeOCSPResponse, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	req, err := ocsp.ParseRequest(data)
+	if err != nil {
+		http.ServeBlob(w, ContentTypeOCSPResponse, ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	h.logger.Debug("serving TLS OCSP response", "serial", req.SerialNumber)
+
+	resp, err := h.ca.OCSPResponse(req, time.Now())
+	if err != nil {
+		h.logger.Warn("unable to create OCSP response", "err", err)
+		http.ServeBlob(w, ContentTypeOCSPResponse, ocsp.InternalErrorErrorResponse)
+		return
+	}
+
+	http.ServeBlob(w, ContentTypeOCSPResponse, resp)
+}
+
+func parseOCSPRequest(r *nethttp.Request) ([]byte, error) {
+	if r.Method != nethttp.MethodGet {
+		return io.ReadAll(io.LimitReader(r.Body, MaxCertificateRequestSize))
+	}
+
+	value := r.PathValue("request")
+	if value == "" {
+		return nil, ErrNoOCSPRequest
+	}
+
+	return base64.StdEncoding.DecodeString(value)
+}
+
+func addRevocationEndpoints(template *x509.Certificate, meta *TLSMeta, base string) {
+	template.CRLDistributionPoints = []string{base + "/" + AuthorityHostname + "/crl?" + meta.AuthorityQuery().Encode()}
+	template.OCSPServer = []string{base + "/" + AuthorityHostname + "/ocsp"}
+}
@@ -0,0 +1,169 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
+	nethttp "net/http"
+	"strconv"
+
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/config"
+	"github.com/UiP9AV6Y/fake-secrets/internal/http"
+)
+
+func (h *TLSHandler) RoutePKCS12(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", "{hostname}", "pkcs12"), h.ServePKCS12
+}
+
+func (h *TLSHandler) ServePKCS12(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseTLSStoreMeta(name, h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated PKCS#12 archive", "meta", meta)
+
+	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	base := http.ParseHeaderRouteURL(r, 2)
+	der, chain, err := h.loadCertificate(&meta.TLSMeta, base)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	pfx, err := pkcs12.Modern.WithRand(h.rand).Encode(key, cert, chain, meta.Password)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data := []byte(base64.StdEncoding.EncodeToString(pfx))
+
+	http.ServeSecret(w, data, meta)
+}
+
+func (h *TLSHandler) RouteKeyStore(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", "{hostname}", "keystores"), h.ServeKeyStore
+}
+
+func (h *TLSHandler) ServeKeyStore(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("hostname")
+	meta, err := ParseTLSKeyStoreMeta(name, h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated Java keystore", "meta", meta)
+
+	_, key, err := LoadHandlerKey(h, &meta.CryptoMeta, h.rand)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	base := http.ParseHeaderRouteURL(r, 2)
+	der, chain, err := h.loadCertificate(&meta.TLSMeta, base)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	entry := keystore.PrivateKeyEntry{
+		CreationTime: h.start,
+		PrivateKey:   pkcs8,
+		CertificateChain: []keystore.Certificate{
+			{Type: "X509", Content: der},
+		},
+	}
+	for _, c := range chain {
+		entry.CertificateChain = append(entry.CertificateChain, keystore.Certificate{
+			Type:    "X509",
+			Content: c.Raw,
+		})
+	}
+
+	ks := keystore.New(keystore.WithOrderedAliases(), keystore.WithCustomRandomNumberGenerator(h.rand))
+	if err := ks.SetPrivateKeyEntry(name, entry, []byte(meta.KeyPassword)); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	h.serveKeyStore(w, ks, meta.Password, meta)
+}
+
+func (h *TLSHandler) RouteTrustStore(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("tls", "truststores"), h.ServeTrustStore
+}
+
+func (h *TLSHandler) ServeTrustStore(w nethttp.ResponseWriter, r *nethttp.Request) {
+	meta, err := ParseTLSTrustStoreMeta(h.start, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated Java truststore", "meta", meta)
+
+	if _, _, err := h.ca.Load(&meta.TLSMeta); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	ks := keystore.New(keystore.WithOrderedAliases(), keystore.WithCustomRandomNumberGenerator(h.rand))
+	for i, c := range h.ca.Roots() {
+		alias := c.Subject.CommonName
+		if ks.IsTrustedCertificateEntry(alias) {
+			alias += " " + strconv.Itoa(i)
+		}
+
+		entry := keystore.TrustedCertificateEntry{
+			CreationTime: h.start,
+			Certificate: keystore.Certificate{
+				Type:    "X509",
+				Content: c.Raw,
+			},
+		}
+
+		if err := ks.SetTrustedCertificateEntry(alias, entry); err != nil {
+			http.ServeError(w, nethttp.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	h.serveKeyStore(w, ks, meta.Password, meta)
+}
+
+func (h *TLSHandler) serveKeyStore(w nethttp.ResponseWriter, ks keystore.KeyStore, password string, meta any) {
+	var buf bytes.Buffer
+	if err := ks.Store(&buf, []byte(password)); err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data := []byte(base64.StdEncoding.EncodeToString(buf.Bytes()))
+
+	http.ServeSecret(w, data, meta)
+}
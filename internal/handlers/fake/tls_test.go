@@ -0,0 +1,970 @@
+package fake_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pavlo-v-chernykh/keystore-go/v4"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/UiP9AV6Y/fake-secrets/internal/assert"
+	"github.com/UiP9AV6Y/fake-secrets/internal/handlers/fake"
+	"github.com/UiP9AV6Y/fake-secrets/internal/io"
+)
+
+func TestTLSHandlerServeCertificate(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveHostname string
+		HaveRequest  []requestOption
+		Want         assert.Assertions[*http.Response]
+	}{
+		"self_signed": {
+			HaveHostname: "self-signed.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509IsCA(false),
+							assert.X509DNSName("self-signed.test"),
+							assert.X509IssuerCommonName(
+								assert.StringEqual(""),
+							),
+						),
+					),
+				),
+			},
+		},
+		"root_authority": {
+			HaveHostname: "root.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "root"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509IsCA(false),
+							assert.X509DNSName("root.test"),
+							assert.X509IssuerCommonName(
+								assert.StringEqual("Acme Co Root CA"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"intermediate_authority": {
+			HaveHostname: "intermediate.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "intermediate"),
+				WithRequestQuery("organization", "Spec"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509IssuerCommonName(
+								assert.StringEqual("Spec Intermediate CA"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"client_usage": {
+			HaveHostname: "client@example.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("usage", "client"),
+				WithRequestQuery("alt_name", "spiffe://example.test/client"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509ExtKeyUsage(x509.ExtKeyUsageClientAuth),
+							assert.X509EmailAddress("client@example.test"),
+							assert.X509URI("spiffe://example.test/client"),
+							assert.X509SubjectCommonName(
+								assert.StringEqual("client@example.test"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"server_client_usage": {
+			HaveHostname: "both.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("usage", "both"),
+				WithRequestQuery("common_name", "Both"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509ExtKeyUsage(x509.ExtKeyUsageServerAuth),
+							assert.X509ExtKeyUsage(x509.ExtKeyUsageClientAuth),
+							assert.X509DNSName("both.test"),
+							assert.X509SubjectCommonName(
+								assert.StringEqual("Both"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"invalid_authority": {
+			HaveHostname: "invalid.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("ca", "unknown"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"unsupported_curve": {
+			HaveHostname: "secp256k1.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("curve", "secp256k1"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"unsupported_authority_curve": {
+			HaveHostname: "secp256k1.test",
+			HaveRequest: []requestOption{
+				WithRequestQuery("ca", "root"),
+				WithRequestQuery("ca_algorithm", "ecdsa"),
+				WithRequestQuery("ca_curve", "secp256k1"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"reserved_hostname": {
+			HaveHostname: "ca",
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPathValue("hostname", test.HaveHostname),
+				WithRequestPath("certificates"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeCertificate(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTLSHandlerServeAuthorityCertificate(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509IsCA(true),
+							assert.X509SubjectCommonName(
+								assert.StringEqual("Acme Co Root CA"),
+							),
+							assert.X509IssuerCommonName(
+								assert.StringEqual("Acme Co Root CA"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"intermediate": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "intermediate"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509Certificate(
+							assert.X509IsCA(true),
+							assert.X509SubjectCommonName(
+								assert.StringEqual("Acme Co Intermediate CA"),
+							),
+							assert.X509IssuerCommonName(
+								assert.StringEqual("Acme Co Root CA"),
+							),
+						),
+					),
+				),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPath("ca"),
+				WithRequestPath("certificates"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeAuthorityCertificate(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTLSHandlerServeChain(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"self_signed": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509CertificateCount(1),
+					),
+				),
+			},
+		},
+		"root_authority": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "root"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509CertificateCount(2),
+					),
+				),
+			},
+		},
+		"intermediate_authority": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "intermediate"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509CertificateCount(3),
+						assert.X509Certificate(
+							assert.X509DNSName("chain.test"),
+						),
+					),
+				),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPathValue("hostname", "chain.test"),
+				WithRequestPath("chains"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeChain(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTLSHandlerServeBundle(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	rnd := io.InfiniteReader([]byte("tls-random-seed"))
+	subject := fake.NewTLSHandler(start, rnd, logger)
+	organizations := []string{"Spec", "Spec", "Test"}
+
+	for _, o := range organizations {
+		req := newRequest(t.Context(),
+			WithRequestPath("tls"),
+			WithRequestPath("ca"),
+			WithRequestPath("certificates"),
+			WithRequestQuery("algorithm", "ecdsa"),
+			WithRequestQuery("organization", o),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeAuthorityCertificate(w, req)
+	}
+
+	req := newRequest(t.Context(),
+		WithRequestPath("tls"),
+		WithRequestPath("bundle"),
+	)
+	w := httptest.NewRecorder()
+
+	subject.ServeBundle(w, req)
+
+	assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("secret",
+				assert.X509CertificateCount(2),
+			),
+		),
+	})
+}
+
+func TestTLSHandlerServeSign(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName: "csr.test",
+		},
+		DNSNames: []string{"csr.test"},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	csr := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"pem_body": {
+			HaveRequest: []requestOption{
+				WithRequestBody("application/pkcs10", csr),
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509CertificateCount(1),
+						assert.X509Certificate(
+							assert.X509DNSName("csr.test"),
+							assert.X509SubjectCommonName(
+								assert.StringEqual("csr.test"),
+							),
+							assert.X509IssuerCommonName(
+								assert.StringEqual("Acme Co Root CA"),
+							),
+						),
+					),
+				),
+			},
+		},
+		"der_body": {
+			HaveRequest: []requestOption{
+				WithRequestBody("application/pkcs10", der),
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+			},
+		},
+		"form_chain": {
+			HaveRequest: []requestOption{
+				WithRequestForm("csr", string(csr)),
+				WithRequestForm("ca", "intermediate"),
+				WithRequestForm("chain", "true"),
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.X509CertificateCount(3),
+					),
+				),
+			},
+		},
+		"missing": {
+			HaveRequest: []requestOption{
+				WithRequestBody("application/pkcs10", nil),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPath("sign"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeSign(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTLSHandlerServeRevoke(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	rnd := io.InfiniteReader([]byte("tls-random-seed"))
+	subject := fake.NewTLSHandler(start, rnd, logger)
+	hostnames := []string{"revoked.test", "revoked.test"}
+
+	for _, n := range hostnames {
+		req := newRequest(t.Context(),
+			WithRequestMethod(http.MethodPost),
+			WithRequestPath("tls"),
+			WithRequestPathValue("hostname", n),
+			WithRequestPath("revoke"),
+			WithRequestQuery("algorithm", "ecdsa"),
+			WithRequestQuery("ca", "root"),
+			WithRequestQuery("reason", "1"),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeRevoke(w, req)
+
+		assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+			assert.HTTPResponseStatusCode(http.StatusOK),
+			assert.HTTPResponseBodyJSON(
+				assertDTOString("secret",
+					assert.X509Certificate(
+						assert.X509DNSName(n),
+					),
+				),
+			),
+		})
+	}
+
+	req := newRequest(t.Context(),
+		WithRequestPath("tls"),
+		WithRequestPath("ca"),
+		WithRequestPath("crl"),
+		WithRequestQuery("algorithm", "ecdsa"),
+	)
+	w := httptest.NewRecorder()
+
+	subject.ServeRevocationList(w, req)
+
+	assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseHeader("Content-Type",
+			assert.StringEqual("application/pkix-crl"),
+		),
+		assert.HTTPResponseBody(
+			assert.X509RevocationList(
+				assert.X509RevokedCount(1),
+			),
+		),
+	})
+}
+
+func TestTLSHandlerServeRevokeSelfSigned(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	rnd := io.InfiniteReader([]byte("tls-random-seed"))
+	subject := fake.NewTLSHandler(start, rnd, logger)
+	req := newRequest(t.Context(),
+		WithRequestMethod(http.MethodPost),
+		WithRequestPath("tls"),
+		WithRequestPathValue("hostname", "self-signed.test"),
+		WithRequestPath("revoke"),
+	)
+	w := httptest.NewRecorder()
+
+	subject.ServeRevoke(w, req)
+
+	assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusBadRequest),
+	})
+}
+
+func assertPKCS12(password string, chain int) assert.Assertion[string] {
+	return func(t *testing.T, got string) {
+		pfx, err := base64.StdEncoding.DecodeString(got)
+		if err != nil {
+			t.Fatalf("malformed base64 input")
+		}
+
+		key, cert, ca, err := pkcs12.DecodeChain(pfx, password)
+		if err != nil {
+			t.Fatalf("malformed PKCS#12 input: %v", err)
+		}
+
+		if key == nil || cert == nil {
+			t.Error("PKCS#12 archive does not contain a private key and certificate")
+		}
+
+		if len(ca) != chain {
+			t.Errorf("PKCS#12 chain length: got %d, want %d", len(ca), chain)
+		}
+	}
+}
+
+func TestTLSHandlerServePKCS12(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertPKCS12("changeit", 0),
+					),
+				),
+			},
+		},
+		"password_chain": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "intermediate"),
+				WithRequestQuery("password", "s3cr3t"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertPKCS12("s3cr3t", 2),
+					),
+				),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPathValue("hostname", "pkcs12.test"),
+				WithRequestPath("pkcs12"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServePKCS12(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func assertKeyStore(password, keyPassword string, chain int) assert.Assertion[string] {
+	return func(t *testing.T, got string) {
+		data, err := base64.StdEncoding.DecodeString(got)
+		if err != nil {
+			t.Fatalf("malformed base64 input")
+		}
+
+		ks := keystore.New()
+		if err := ks.Load(bytes.NewReader(data), []byte(password)); err != nil {
+			t.Fatalf("malformed keystore input: %v", err)
+		}
+
+		aliases := ks.Aliases()
+		if len(aliases) != 1 {
+			t.Fatalf("keystore entry count: got %d, want 1", len(aliases))
+		}
+
+		entry, err := ks.GetPrivateKeyEntry(aliases[0], []byte(keyPassword))
+		if err != nil {
+			t.Fatalf("malformed keystore entry: %v", err)
+		}
+
+		if len(entry.CertificateChain) != chain+1 {
+			t.Errorf("keystore chain length: got %d, want %d", len(entry.CertificateChain), chain+1)
+		}
+	}
+}
+
+func TestTLSHandlerServeKeyStore(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertKeyStore("changeit", "changeit", 0),
+					),
+				),
+			},
+		},
+		"passwords_chain": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("ca", "intermediate"),
+				WithRequestQuery("password", "s3cr3t"),
+				WithRequestQuery("key_password", "k3y"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertKeyStore("s3cr3t", "k3y", 2),
+					),
+				),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPathValue("hostname", "keystore.test"),
+				WithRequestPath("keystores"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeKeyStore(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTLSHandlerServeTrustStore(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	rnd := io.InfiniteReader([]byte("tls-random-seed"))
+	subject := fake.NewTLSHandler(start, rnd, logger)
+	organizations := []string{"Spec", "Test"}
+
+	for _, o := range organizations {
+		req := newRequest(t.Context(),
+			WithRequestPath("tls"),
+			WithRequestPath("truststores"),
+			WithRequestQuery("algorithm", "ecdsa"),
+			WithRequestQuery("organization", o),
+			WithRequestQuery("password", "trust"),
+		)
+		w := httptest.NewRecorder()
+
+		subject.ServeTrustStore(w, req)
+
+		assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+			assert.HTTPResponseStatusCode(http.StatusOK),
+		})
+	}
+
+	req := newRequest(t.Context(),
+		WithRequestPath("tls"),
+		WithRequestPath("truststores"),
+		WithRequestQuery("algorithm", "ecdsa"),
+	)
+	w := httptest.NewRecorder()
+
+	subject.ServeTrustStore(w, req)
+
+	assert.Assert(t, w.Result(), assert.Assertions[*http.Response]{
+		assert.HTTPResponseStatusCode(http.StatusOK),
+		assert.HTTPResponseBodyJSON(
+			assertDTOString("secret", func(t *testing.T, got string) {
+				data, err := base64.StdEncoding.DecodeString(got)
+				if err != nil {
+					t.Fatalf("malformed base64 input")
+				}
+
+				ks := keystore.New()
+				if err := ks.Load(bytes.NewReader(data), []byte("changeit")); err != nil {
+					t.Fatalf("malformed truststore input: %v", err)
+				}
+
+				if got := len(ks.Aliases()); got != 3 {
+					t.Errorf("truststore entry count: got %d, want 3", got)
+				}
+			}),
+		),
+	})
+}
+
+func assertPEMPrivateKey(typ, passphrase string, parse func([]byte) error) assert.Assertion[string] {
+	return func(t *testing.T, got string) {
+		block, _ := pem.Decode([]byte(got))
+		if block == nil || block.Type != typ {
+			t.Fatalf("malformed PEM %s input", typ)
+		}
+
+		der := block.Bytes
+		if passphrase != "" {
+			var err error
+			if der, err = x509.DecryptPEMBlock(block, []byte(passphrase)); err != nil { //nolint:staticcheck
+				t.Fatalf("malformed encrypted PEM block: %v", err)
+			}
+		}
+
+		if err := parse(der); err != nil {
+			t.Errorf("malformed %s: %v", typ, err)
+		}
+	}
+}
+
+func TestTLSHandlerServePrivateKey(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	start := time.Unix(0, 0).UTC()
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"default": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.PKCS8PrivateKey(""),
+					),
+				),
+			},
+		},
+		"passphrase": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ed25519"),
+				WithRequestQuery("passphrase", "s3cr3t"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.PKCS8PrivateKey("s3cr3t"),
+					),
+				),
+			},
+		},
+		"format_pkcs1": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "rsa"),
+				WithRequestQuery("length", "1024"),
+				WithRequestQuery("format", "pkcs1"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertPEMPrivateKey("RSA PRIVATE KEY", "", func(der []byte) error {
+							_, err := x509.ParsePKCS1PrivateKey(der)
+							return err
+						}),
+					),
+				),
+			},
+		},
+		"format_sec1_passphrase": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ecdsa"),
+				WithRequestQuery("format", "sec1"),
+				WithRequestQuery("passphrase", "s3cr3t"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assertPEMPrivateKey("EC PRIVATE KEY", "s3cr3t", func(der []byte) error {
+							_, err := x509.ParseECPrivateKey(der)
+							return err
+						}),
+					),
+				),
+			},
+		},
+		"format_der": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ed25519"),
+				WithRequestQuery("format", "der"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", func(t *testing.T, got string) {
+						der, err := base64.StdEncoding.DecodeString(got)
+						if err != nil {
+							t.Fatalf("malformed base64 input")
+						}
+
+						if _, err := x509.ParsePKCS8PrivateKey(der); err != nil {
+							t.Errorf("malformed PKCS#8 private key: %v", err)
+						}
+					}),
+				),
+			},
+		},
+		"format_mismatch": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("algorithm", "ed25519"),
+				WithRequestQuery("format", "sec1"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"format_invalid": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("format", "jks"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("tls-random-seed"))
+			subject := fake.NewTLSHandler(start, rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("tls"),
+				WithRequestPathValue("hostname", "key.test"),
+				WithRequestPath("keys"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServePrivateKey(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
@@ -50,6 +50,7 @@ func (h *TOTPHandler) ServePrivateKey(w nethttp.ResponseWriter, r *nethttp.Reque
 		AccountName: meta.Subject,
 		SecretSize:  uint(meta.Length),
 		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
 		Period:      uint(meta.ValidFor),
 		Random:      h.rand,
 	}
@@ -83,6 +84,7 @@ func (h *TOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 		AccountName: meta.Subject,
 		SecretSize:  uint(meta.Length),
 		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
 		Period:      uint(meta.ValidFor),
 		Random:      h.rand,
 	}
@@ -94,6 +96,8 @@ func (h *TOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 
 	opts := totp.ValidateOpts{
 		Algorithm: meta.Algorithm.OTPAlgorithm(),
+		Digits:    key.Digits(),
+		Encoder:   key.Encoder(),
 		Period:    uint(meta.ValidFor),
 	}
 	now := time.Unix(meta.ValidAt, 0)
@@ -107,3 +111,105 @@ func (h *TOTPHandler) ServeCode(w nethttp.ResponseWriter, r *nethttp.Request) {
 
 	http.ServeSecret(w, data, meta)
 }
+
+func (h *TOTPHandler) RouteVerify(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return nethttp.MethodPost + " " + cfg.HandlerPattern("totp", "{account}", "verify"), h.ServeVerify
+}
+
+func (h *TOTPHandler) ServeVerify(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseTOTPVerifyMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("verifying TOTP code", "meta", meta)
+
+	req := &cache.TOTPLoader{
+		Issuer:      meta.Organization,
+		AccountName: meta.Subject,
+		SecretSize:  uint(meta.Length),
+		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
+		Period:      uint(meta.ValidFor),
+		Random:      h.rand,
+	}
+	key, err := h.keys.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	data, err := verifyTOTPCode(key, meta)
+
+	serveOTPVerification(w, data, err)
+}
+
+func verifyTOTPCode(key *otp.Key, meta *TOTPVerifyMeta) (*otpVerification, error) {
+	opts := totp.ValidateOpts{
+		Algorithm: meta.Algorithm.OTPAlgorithm(),
+		Digits:    key.Digits(),
+		Encoder:   key.Encoder(),
+		Period:    uint(meta.ValidFor),
+	}
+	now := time.Unix(meta.ValidAt, 0).UTC()
+	step := meta.ValidAt / meta.ValidFor
+	drifts := []int64{0}
+
+	for i := int64(1); i <= int64(meta.Skew); i++ {
+		drifts = append(drifts, i, -i)
+	}
+
+	for _, drift := range drifts {
+		at := now.Add(time.Duration(drift*meta.ValidFor) * time.Second)
+		ok, err := totp.ValidateCustom(meta.Code, key.Secret(), at, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			result := &otpVerification{
+				Valid:   true,
+				Counter: uint64(step + drift),
+				Drift:   drift,
+			}
+
+			return result, nil
+		}
+	}
+
+	return &otpVerification{}, nil
+}
+
+func (h *TOTPHandler) RouteQRCode(cfg *config.Config) (string, nethttp.HandlerFunc) {
+	return cfg.HandlerPattern("totp", "{account}", "qrcodes"), h.ServeQRCode
+}
+
+func (h *TOTPHandler) ServeQRCode(w nethttp.ResponseWriter, r *nethttp.Request) {
+	name := r.PathValue("account")
+	meta, err := ParseTOTPQRCodeMeta(name, r)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Debug("serving generated TOTP QR code", "meta", meta)
+
+	req := &cache.TOTPLoader{
+		Issuer:      meta.Organization,
+		AccountName: meta.Subject,
+		SecretSize:  uint(meta.Length),
+		Algorithm:   meta.Algorithm,
+		Digits:      meta.Digits,
+		Period:      uint(meta.ValidFor),
+		Random:      h.rand,
+	}
+	key, err := h.keys.Load(req)
+	if err != nil {
+		http.ServeError(w, nethttp.StatusInternalServerError, err)
+		return
+	}
+
+	serveOTPQRCode(w, key, &meta.QRCodeMeta, meta)
+}
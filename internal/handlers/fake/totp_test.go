@@ -1,9 +1,13 @@
 package fake_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"image/png"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"testing"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/assert"
@@ -84,6 +88,48 @@ func TestTOTPHandlerServePrivateKey(t *testing.T) {
 				),
 			},
 		},
+		"digits": {
+			HaveAccount: "digits",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "8"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.OTP(
+							assert.OTPDigits(8),
+						),
+					),
+				),
+			},
+		},
+		"steam": {
+			HaveAccount: "steam",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "steam"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret",
+						assert.OTP(
+							assert.OTPDigits(5),
+							assert.OTPEncoder("steam"),
+						),
+					),
+				),
+			},
+		},
+		"invalid_digits": {
+			HaveAccount: "digits",
+			HaveRequest: []requestOption{
+				WithRequestQuery("digits", "7"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
 	}
 
 	for name, test := range testCases {
@@ -111,3 +157,317 @@ func TestTOTPHandlerServePrivateKey(t *testing.T) {
 		t.Run(name, scenario)
 	}
 }
+
+func TestTOTPHandlerServeVerify(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	testCases := map[string]struct {
+		HaveCode    string
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"current": {
+			HaveRequest: []requestOption{
+				WithRequestForm("valid_at", "1000"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+					assertDTONumber("counter", 33),
+					assertDTONumber("drift", 0),
+				),
+			},
+		},
+		"skew": {
+			HaveRequest: []requestOption{
+				WithRequestForm("valid_at", "1030"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+					assertDTONumber("counter", 33),
+					assertDTONumber("drift", -1),
+				),
+			},
+		},
+		"outside_skew": {
+			HaveRequest: []requestOption{
+				WithRequestForm("valid_at", "1090"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", false),
+					assertDTONoField("counter"),
+				),
+			},
+		},
+		"wide_skew": {
+			HaveRequest: []requestOption{
+				WithRequestForm("valid_at", "1090"),
+				WithRequestForm("skew", "3"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+					assertDTONumber("counter", 33),
+					assertDTONumber("drift", -3),
+				),
+			},
+		},
+		"excessive_skew": {
+			HaveRequest: []requestOption{
+				WithRequestForm("skew", "11"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"length": {
+			HaveCode: "123",
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("otp-random-seed"))
+			subject := fake.NewTOTPHandler(rnd, logger)
+			req := newRequest(t.Context(),
+				WithRequestPath("totp"),
+				WithRequestPathValue("account", "verify"),
+				WithRequestPath("codes"),
+				WithRequestQuery("valid_at", "1000"),
+			)
+			w := httptest.NewRecorder()
+
+			subject.ServeCode(w, req)
+
+			code := test.HaveCode
+			if code == "" {
+				code = decodeSecret(t, w.Result())
+			}
+
+			reqopt := []requestOption{
+				WithRequestMethod(http.MethodPost),
+				WithRequestPath("totp"),
+				WithRequestPathValue("account", "verify"),
+				WithRequestPath("verify"),
+				WithRequestForm("code", code),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			w = httptest.NewRecorder()
+
+			subject.ServeVerify(w, newRequest(t.Context(), reqopt...))
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTOTPHandlerServeQRCode(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	pngSize := func(want int) assert.Assertion[[]byte] {
+		return func(t *testing.T, got []byte) {
+			img, err := png.Decode(bytes.NewReader(got))
+			if err != nil {
+				t.Fatalf("malformed PNG image: %v", err)
+			}
+
+			if bounds := img.Bounds(); bounds.Dx() != want || bounds.Dy() != want {
+				t.Errorf("PNG image size: got %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), want, want)
+			}
+		}
+	}
+	testCases := map[string]struct {
+		HaveRequest []requestOption
+		Want        assert.Assertions[*http.Response]
+	}{
+		"png": {
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseHeader("Content-Type",
+					assert.StringEqual("image/png"),
+				),
+				assert.HTTPResponseBody(pngSize(200)),
+			},
+		},
+		"size": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("size", "320"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBody(pngSize(320)),
+			},
+		},
+		"base64": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("encoding", "base64"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOString("secret", func(t *testing.T, got string) {
+						data, err := base64.StdEncoding.DecodeString(got)
+						if err != nil {
+							t.Fatalf("malformed base64 data: %v", err)
+						}
+
+						pngSize(200)(t, data)
+					}),
+				),
+			},
+		},
+		"svg": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("format", "svg"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseHeader("Content-Type",
+					assert.StringEqual("image/svg+xml"),
+				),
+				assert.HTTPResponseBody(func(t *testing.T, got []byte) {
+					if !bytes.HasPrefix(got, []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="200" height="200"`)) {
+						t.Errorf("SVG image: got %.80s", got)
+					}
+				}),
+			},
+		},
+		"svg_vector": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("format", "svg"),
+				WithRequestQuery("size", "3000"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBody(func(t *testing.T, got []byte) {
+					if !bytes.Contains(got, []byte(`width="3000" height="3000" viewBox="0 0 `)) {
+						t.Errorf("SVG image: got %.120s", got)
+					}
+
+					if len(got) > 16*1024 {
+						t.Errorf("SVG image size: got %d bytes, want a size independent vector image", len(got))
+					}
+				}),
+			},
+		},
+		"too_large": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("size", "100000"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"too_small": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("size", "10"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+		"format": {
+			HaveRequest: []requestOption{
+				WithRequestQuery("format", "gif"),
+			},
+			Want: assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusBadRequest),
+			},
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("otp-random-seed"))
+			subject := fake.NewTOTPHandler(rnd, logger)
+			reqopt := []requestOption{
+				WithRequestPath("totp"),
+				WithRequestPathValue("account", "qrcode"),
+				WithRequestPath("qrcodes"),
+			}
+
+			if len(test.HaveRequest) > 0 {
+				reqopt = append(reqopt, test.HaveRequest...)
+			}
+
+			req := newRequest(t.Context(), reqopt...)
+			w := httptest.NewRecorder()
+
+			subject.ServeQRCode(w, req)
+
+			assert.Assert(t, w.Result(), test.Want)
+		}
+
+		t.Run(name, scenario)
+	}
+}
+
+func TestTOTPHandlerServeCode(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	testCases := map[string]struct {
+		HaveDigits string
+		WantCode   *regexp.Regexp
+	}{
+		"default": {
+			WantCode: regexp.MustCompile(`^[0-9]{6}$`),
+		},
+		"eight": {
+			HaveDigits: "8",
+			WantCode:   regexp.MustCompile(`^[0-9]{8}$`),
+		},
+		"steam": {
+			HaveDigits: "steam",
+			WantCode:   regexp.MustCompile(`^[2-9BCDFGHJKMNPQRTVWXY]{5}$`),
+		},
+	}
+
+	for name, test := range testCases {
+		scenario := func(t *testing.T) {
+			rnd := io.InfiniteReader([]byte("otp-random-seed"))
+			subject := fake.NewTOTPHandler(rnd, logger)
+			serve := func(handler http.HandlerFunc, endpoint string, reqopt ...requestOption) *http.Response {
+				reqopt = append([]requestOption{
+					WithRequestPath("totp"),
+					WithRequestPathValue("account", "code"),
+					WithRequestPath(endpoint),
+					WithRequestForm("valid_at", "1000"),
+					WithRequestForm("digits", test.HaveDigits),
+				}, reqopt...)
+				w := httptest.NewRecorder()
+
+				handler(w, newRequest(t.Context(), reqopt...))
+
+				return w.Result()
+			}
+
+			code := decodeSecret(t, serve(subject.ServeCode, "codes"))
+			if !test.WantCode.MatchString(code) {
+				t.Fatalf("TOTP code: got %q, want match for %s", code, test.WantCode)
+			}
+
+			verify := serve(subject.ServeVerify, "verify", WithRequestMethod(http.MethodPost), WithRequestForm("code", code))
+
+			assert.Assert(t, verify, assert.Assertions[*http.Response]{
+				assert.HTTPResponseStatusCode(http.StatusOK),
+				assert.HTTPResponseBodyJSON(
+					assertDTOBool("valid", true),
+				),
+			})
+		}
+
+		t.Run(name, scenario)
+	}
+}
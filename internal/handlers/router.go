@@ -17,10 +17,11 @@ func NewRouter(cfg *config.Config, logger *slog.Logger) (http.Handler, error) {
 	random := cfg.RandomGenerator()
 	status := health.NewHandler(now, logger)
 	generator := fake.NewGeneratorHandler(random, logger)
-	ssh := fake.NewSSHHandler(random, logger)
+	ssh := fake.NewSSHHandler(now, random, logger)
 	tls := fake.NewTLSHandler(now, random, logger)
 	jwt := fake.NewJWTHandler(now, random, logger)
 	hotp := fake.NewHOTPHandler(random, logger)
+	mfa := fake.NewMFAHandler(random, logger)
 	totp := fake.NewTOTPHandler(random, logger)
 
 	router.HandleFunc("/", index.ServeHTTP)
@@ -32,15 +33,48 @@ func NewRouter(cfg *config.Config, logger *slog.Logger) (http.Handler, error) {
 	router.HandleFunc(generator.RouteSeededAPIKey(cfg))
 	router.HandleFunc(ssh.RouteCertificate(cfg))
 	router.HandleFunc(ssh.RoutePrivateKey(cfg))
+	router.HandleFunc(ssh.RouteAuthorityPublicKey(cfg))
+	router.HandleFunc(ssh.RouteKnownHosts(cfg))
+	router.HandleFunc(ssh.RouteAuthorizedKeys(cfg))
 	router.HandleFunc(tls.RouteCertificate(cfg))
 	router.HandleFunc(tls.RoutePrivateKey(cfg))
+	router.HandleFunc(tls.RouteAuthorityCertificate(cfg))
+	router.HandleFunc(tls.RouteAuthorityPrivateKey(cfg))
+	router.HandleFunc(tls.RouteChain(cfg))
+	router.HandleFunc(tls.RouteBundle(cfg))
+	router.HandleFunc(tls.RouteSign(cfg))
+	router.HandleFunc(tls.RouteRevoke(cfg))
+	router.HandleFunc(tls.RouteRevocationList(cfg))
+	router.HandleFunc(tls.RouteOCSP(cfg))
+	router.HandleFunc(tls.RouteOCSPRequest(cfg))
+	router.HandleFunc(tls.RoutePKCS12(cfg))
+	router.HandleFunc(tls.RouteKeyStore(cfg))
+	router.HandleFunc(tls.RouteTrustStore(cfg))
 	router.HandleFunc(jwt.RouteCertificate(cfg))
 	router.HandleFunc(jwt.RoutePrivateKey(cfg))
+	router.HandleFunc(jwt.RouteSecret(cfg))
+	router.HandleFunc(jwt.RouteRotation(cfg))
+	router.HandleFunc(jwt.RouteVerify(cfg))
+	router.HandleFunc(jwt.RouteDiscovery(cfg))
+	router.HandleFunc(jwt.RouteJWKS(cfg))
+	router.HandleFunc(jwt.RouteOAuth2Authorize(cfg))
+	router.HandleFunc(jwt.RouteOAuth2Token(cfg))
+	router.HandleFunc(jwt.RouteOAuth2Introspection(cfg))
+	router.HandleFunc(jwt.RouteOAuth2Revocation(cfg))
+	router.HandleFunc(jwt.RouteOAuth2UserInfo(cfg))
+	router.HandleFunc(jwt.RouteIssuerRotation(cfg))
 	router.HandleFunc(jwt.RouteToken(cfg))
 	router.HandleFunc(hotp.RoutePrivateKey(cfg))
+	router.HandleFunc(hotp.RouteQRCode(cfg))
 	router.HandleFunc(hotp.RouteCode(cfg))
+	router.HandleFunc(hotp.RouteVerify(cfg))
+	router.HandleFunc(hotp.RouteResync(cfg))
 	router.HandleFunc(totp.RoutePrivateKey(cfg))
+	router.HandleFunc(totp.RouteQRCode(cfg))
 	router.HandleFunc(totp.RouteCode(cfg))
+	router.HandleFunc(totp.RouteVerify(cfg))
+	router.HandleFunc(mfa.RouteRecoveryCodes(cfg))
+	router.HandleFunc(mfa.RouteRedeem(cfg))
 	router.Handle(status.Route(cfg), status)
 
 	if cfg.StorageDir != "" {
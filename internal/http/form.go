@@ -1,13 +1,33 @@
 package http
 
 import (
+	"mime"
 	nethttp "net/http"
 	"strconv"
+	"strings"
 
 	"github.com/UiP9AV6Y/fake-secrets/internal/crypto"
 	"github.com/UiP9AV6Y/fake-secrets/internal/hash"
 )
 
+func IsFormRequest(r *nethttp.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil {
+		return false
+	}
+
+	return mt == ContentTypeForm || mt == "multipart/form-data"
+}
+
+func IsJSONRequest(r *nethttp.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get(HeaderContentType))
+	if err != nil {
+		return false
+	}
+
+	return mt == ContentTypeJSON
+}
+
 func ParseFormString(r *nethttp.Request, field, fallback string) string {
 	value := r.FormValue(field)
 	if value == "" {
@@ -45,6 +65,20 @@ func ParseFormInt(r *nethttp.Request, field string, fallback int64) (int64, erro
 	return result, nil
 }
 
+func ParseFormUint(r *nethttp.Request, field string, fallback uint64) (uint64, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
 func ParseFormHashAlgorithm(r *nethttp.Request, field string, fallback hash.Algorithm) (hash.Algorithm, error) {
 	value := r.FormValue(field)
 	if value == "" {
@@ -86,3 +120,171 @@ func ParseFormECDSACurve(r *nethttp.Request, field string, fallback crypto.ECDSA
 
 	return result, nil
 }
+
+func ParseFormJWSAlgorithm(r *nethttp.Request, field string, fallback crypto.JWSAlgorithm) (crypto.JWSAlgorithm, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseJWSAlgorithm(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormJWEAlgorithm(r *nethttp.Request, field string, fallback crypto.JWEAlgorithm) (crypto.JWEAlgorithm, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseJWEAlgorithm(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormJWEEncryption(r *nethttp.Request, field string, fallback crypto.JWEEncryption) (crypto.JWEEncryption, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseJWEEncryption(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormOTPDigits(r *nethttp.Request, field string, fallback crypto.OTPDigits) (crypto.OTPDigits, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseOTPDigits(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormPasswordHash(r *nethttp.Request, field string, fallback crypto.PasswordHash) (crypto.PasswordHash, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParsePasswordHash(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormAuthority(r *nethttp.Request, field string, fallback crypto.Authority) (crypto.Authority, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseAuthority(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormUsage(r *nethttp.Request, field string, fallback crypto.Usage) (crypto.Usage, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseUsage(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormStrings(r *nethttp.Request, field string) []string {
+	values := r.Form[field]
+	result := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+func ParseFormSSHCertType(r *nethttp.Request, field string, fallback crypto.SSHCertType) (crypto.SSHCertType, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseSSHCertType(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
+
+func ParseFormOptions(r *nethttp.Request, field string) map[string]string {
+	values := ParseFormStrings(r, field)
+	result := make(map[string]string, len(values))
+
+	for _, v := range values {
+		name, value, _ := strings.Cut(v, "=")
+		result[name] = value
+	}
+
+	return result
+}
+
+func ParseFormPrefixed(r *nethttp.Request, prefix string) map[string][]string {
+	result := map[string][]string{}
+
+	for k := range r.Form {
+		name, ok := strings.CutPrefix(k, prefix)
+		if !ok || name == "" {
+			continue
+		}
+
+		if values := ParseFormStrings(r, k); len(values) > 0 {
+			result[name] = values
+		}
+	}
+
+	return result
+}
+
+func ParseFormKeyFormat(r *nethttp.Request, field string, fallback crypto.KeyFormat) (crypto.KeyFormat, error) {
+	value := r.FormValue(field)
+	if value == "" {
+		return fallback, nil
+	}
+
+	result, err := crypto.ParseKeyFormat(value)
+	if err != nil {
+		return fallback, err
+	}
+
+	return result, nil
+}
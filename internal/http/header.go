@@ -2,6 +2,7 @@ package http
 
 import (
 	nethttp "net/http"
+	"strings"
 )
 
 const (
@@ -23,3 +24,18 @@ func ParseHeaderBaseURL(r *nethttp.Request) string {
 
 	return scheme + "://" + host
 }
+
+func ParseHeaderRouteURL(r *nethttp.Request, trim int) string {
+	path := strings.TrimSuffix(r.URL.EscapedPath(), "/")
+
+	for range trim {
+		i := strings.LastIndexByte(path, '/')
+		if i < 0 {
+			break
+		}
+
+		path = path[:i]
+	}
+
+	return ParseHeaderBaseURL(r) + path
+}
@@ -9,6 +9,7 @@ import (
 const (
 	HeaderContentType = "Content-Type"
 	ContentTypeJSON   = "application/json"
+	ContentTypeForm   = "application/x-www-form-urlencoded"
 )
 
 func ServeError(w nethttp.ResponseWriter, code int, err error) {
@@ -46,3 +47,10 @@ func ServeJSON(w nethttp.ResponseWriter, dto any) {
 
 	_ = json.NewEncoder(w).Encode(dto)
 }
+
+func ServeBlob(w nethttp.ResponseWriter, contentType string, data []byte) {
+	w.Header().Set(HeaderContentType, contentType)
+	w.WriteHeader(nethttp.StatusOK)
+
+	_, _ = w.Write(data)
+}